@@ -0,0 +1,254 @@
+package br
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Tipos de boleto
+const (
+	BoletoBancario    = "bancario"
+	BoletoArrecadacao = "arrecadacao"
+)
+
+var (
+	// Fator 1000 no ciclo original (base 07/10/1997) e no ciclo iniciado quando o fator chegou a 9999
+	fatorBaseOriginal = time.Date(2000, 7, 3, 0, 0, 0, 0, time.UTC)
+	fatorBaseAtual    = time.Date(2025, 2, 22, 0, 0, 0, 0, time.UTC)
+)
+
+var bancos = map[string]string{
+	"001": "Banco do Brasil",
+	"004": "Banco do Nordeste",
+	"021": "Banestes",
+	"033": "Santander",
+	"041": "Banrisul",
+	"070": "BRB",
+	"077": "Banco Inter",
+	"104": "Caixa Econômica Federal",
+	"208": "BTG Pactual",
+	"212": "Banco Original",
+	"237": "Bradesco",
+	"260": "Nu Pagamentos",
+	"290": "PagSeguro",
+	"318": "Banco BMG",
+	"323": "Mercado Pago",
+	"336": "C6 Bank",
+	"341": "Itaú Unibanco",
+	"380": "PicPay",
+	"422": "Banco Safra",
+	"623": "Banco Pan",
+	"655": "Banco Votorantim",
+	"745": "Citibank",
+	"748": "Sicredi",
+	"756": "Sicoob",
+}
+
+var segmentosArrecadacao = map[byte]string{
+	'1': "Prefeituras",
+	'2': "Saneamento",
+	'3': "Energia elétrica e gás",
+	'4': "Telecomunicações",
+	'5': "Órgãos governamentais",
+	'6': "Carnês e assemelhados",
+	'7': "Multas de trânsito",
+	'9': "Uso exclusivo do banco",
+}
+
+type Boleto struct {
+	Tipo            string  `json:"tipo"` // bancario ou arrecadacao
+	CodigoBarras    string  `json:"codigo_barras"`
+	LinhaDigitavel  string  `json:"linha_digitavel"`
+	Valor           float64 `json:"valor"`
+	ValorReferencia bool    `json:"valor_referencia,omitempty"` // valor em quantidade de moeda, não em reais
+	Vencimento      *string `json:"vencimento,omitempty"`       // YYYY-MM-DD
+	FatorVencimento int     `json:"fator_vencimento,omitempty"`
+	BancoCodigo     string  `json:"banco_codigo,omitempty"`
+	BancoNome       string  `json:"banco_nome,omitempty"`
+	CampoLivre      string  `json:"campo_livre,omitempty"`
+	Segmento        string  `json:"segmento,omitempty"`
+	SegmentoNome    string  `json:"segmento_nome,omitempty"`
+	Empresa         string  `json:"empresa,omitempty"` // código do órgão/empresa ou início do CNPJ (segmento 6)
+}
+
+// ParseBoleto valida um boleto a partir do código de barras (44 dígitos) ou da linha digitável
+// (47 dígitos para boletos bancários, 48 para arrecadação). A data de referência escolhe o ciclo
+// do fator de vencimento mais próximo.
+func ParseBoleto(codigo string, referencia time.Time) (*Boleto, error) {
+	digits := onlyDigits(codigo)
+
+	switch len(digits) {
+	case 44:
+		if digits[0] == '8' {
+			return parseArrecadacao(digits, referencia)
+		}
+		return parseBancario(digits, referencia)
+	case 47:
+		barcode, err := bancarioLinhaToBarcode(digits)
+		if err != nil {
+			return nil, err
+		}
+		return parseBancario(barcode, referencia)
+	case 48:
+		if digits[0] != '8' {
+			return nil, fmt.Errorf("linha digitável de 48 dígitos deve começar com 8 (arrecadação)")
+		}
+		barcode, err := arrecadacaoLinhaToBarcode(digits)
+		if err != nil {
+			return nil, err
+		}
+		return parseArrecadacao(barcode, referencia)
+	default:
+		return nil, fmt.Errorf("código deve ter 44 (código de barras), 47 ou 48 dígitos (linha digitável), recebido %d", len(digits))
+	}
+}
+
+// bancarioDV calcula o dígito geral do código de barras bancário (posição 5)
+func bancarioDV(barcode string) int {
+	dv := 11 - mod11Sum(barcode[:4]+barcode[5:])%11
+	if dv == 0 || dv == 10 || dv == 11 {
+		return 1
+	}
+	return dv
+}
+
+// bancarioLinhaToBarcode valida os dígitos dos campos 1 a 3 e remonta o código de barras
+func bancarioLinhaToBarcode(linha string) (string, error) {
+	campos := []struct{ dados, dv string }{
+		{linha[0:9], linha[9:10]},
+		{linha[10:20], linha[20:21]},
+		{linha[21:31], linha[31:32]},
+	}
+	for i, campo := range campos {
+		if strconv.Itoa(mod10(campo.dados)) != campo.dv {
+			return "", fmt.Errorf("dígito verificador do campo %d inválido", i+1)
+		}
+	}
+
+	barcode := linha[0:4] + linha[32:33] + linha[33:47] + linha[4:9] + linha[10:20] + linha[21:31]
+	return barcode, nil
+}
+
+func bancarioBarcodeToLinha(barcode string) string {
+	campo1 := barcode[0:4] + barcode[19:24]
+	campo2 := barcode[24:34]
+	campo3 := barcode[34:44]
+	return campo1 + strconv.Itoa(mod10(campo1)) +
+		campo2 + strconv.Itoa(mod10(campo2)) +
+		campo3 + strconv.Itoa(mod10(campo3)) +
+		barcode[4:5] + barcode[5:19]
+}
+
+// vencimentoPorFator converte o fator de vencimento, escolhendo entre o ciclo original e o
+// ciclo reiniciado em 22/02/2025 a data mais próxima da referência
+func vencimentoPorFator(fator int, referencia time.Time) time.Time {
+	original := fatorBaseOriginal.AddDate(0, 0, fator-1000)
+	atual := fatorBaseAtual.AddDate(0, 0, fator-1000)
+	if math.Abs(atual.Sub(referencia).Hours()) < math.Abs(original.Sub(referencia).Hours()) {
+		return atual
+	}
+	return original
+}
+
+func parseBancario(barcode string, referencia time.Time) (*Boleto, error) {
+	if barcode[3] != '9' {
+		return nil, fmt.Errorf("código de moeda %c não suportado (esperado 9, real)", barcode[3])
+	}
+	if strconv.Itoa(bancarioDV(barcode)) != barcode[4:5] {
+		return nil, fmt.Errorf("dígito verificador geral inválido")
+	}
+
+	fator, _ := strconv.Atoi(barcode[5:9])
+	valor, _ := strconv.ParseInt(barcode[9:19], 10, 64)
+
+	boleto := &Boleto{
+		Tipo:            BoletoBancario,
+		CodigoBarras:    barcode,
+		LinhaDigitavel:  bancarioBarcodeToLinha(barcode),
+		Valor:           float64(valor) / 100,
+		FatorVencimento: fator,
+		BancoCodigo:     barcode[0:3],
+		BancoNome:       bancos[barcode[0:3]],
+		CampoLivre:      barcode[19:44],
+	}
+	// Fator zerado indica boleto sem vencimento
+	if fator >= 1000 {
+		vencimento := vencimentoPorFator(fator, referencia).Format("2006-01-02")
+		boleto.Vencimento = &vencimento
+	}
+	return boleto, nil
+}
+
+// arrecadacaoDV calcula o dígito pelo módulo indicado na posição 3 (6/7: módulo 10, 8/9: módulo 11)
+func arrecadacaoDV(identificador byte, digits string) (int, error) {
+	switch identificador {
+	case '6', '7':
+		return mod10(digits), nil
+	case '8', '9':
+		resto := mod11Sum(digits) % 11
+		if resto == 0 || resto == 1 {
+			return 0, nil
+		}
+		if resto == 10 {
+			return 1, nil
+		}
+		return 11 - resto, nil
+	default:
+		return 0, fmt.Errorf("identificador de valor %c inválido (esperado 6, 7, 8 ou 9)", identificador)
+	}
+}
+
+// arrecadacaoLinhaToBarcode valida os quatro blocos de 11 dígitos + DV e remonta o código de barras
+func arrecadacaoLinhaToBarcode(linha string) (string, error) {
+	barcode := ""
+	for i := 0; i < 4; i++ {
+		bloco := linha[i*12 : i*12+11]
+		dv, err := arrecadacaoDV(linha[2], bloco)
+		if err != nil {
+			return "", err
+		}
+		if strconv.Itoa(dv) != linha[i*12+11:i*12+12] {
+			return "", fmt.Errorf("dígito verificador do bloco %d inválido", i+1)
+		}
+		barcode += bloco
+	}
+	return barcode, nil
+}
+
+func parseArrecadacao(barcode string, referencia time.Time) (*Boleto, error) {
+	dv, err := arrecadacaoDV(barcode[2], barcode[:3]+barcode[4:])
+	if err != nil {
+		return nil, err
+	}
+	if strconv.Itoa(dv) != barcode[3:4] {
+		return nil, fmt.Errorf("dígito verificador geral inválido")
+	}
+
+	linha := ""
+	for i := 0; i < 4; i++ {
+		bloco := barcode[i*11 : i*11+11]
+		blocoDV, _ := arrecadacaoDV(barcode[2], bloco)
+		linha += bloco + strconv.Itoa(blocoDV)
+	}
+
+	valor, _ := strconv.ParseInt(barcode[4:15], 10, 64)
+	boleto := &Boleto{
+		Tipo:            BoletoArrecadacao,
+		CodigoBarras:    barcode,
+		LinhaDigitavel:  linha,
+		Valor:           float64(valor) / 100,
+		ValorReferencia: barcode[2] == '7' || barcode[2] == '9',
+		Segmento:        barcode[1:2],
+		SegmentoNome:    segmentosArrecadacao[barcode[1]],
+		Empresa:         barcode[15:19],
+		CampoLivre:      barcode[19:44],
+	}
+	if barcode[1] == '6' {
+		// Segmento 6 identifica a empresa pelos 8 primeiros dígitos do CNPJ
+		boleto.Empresa = barcode[15:23]
+		boleto.CampoLivre = barcode[23:44]
+	}
+	return boleto, nil
+}
@@ -0,0 +1,168 @@
+package br
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseBoleto(t *testing.T) {
+	tests := []struct {
+		name           string
+		codigo         string
+		referencia     string
+		tipo           string
+		codigoBarras   string
+		linhaDigitavel string
+		valor          float64
+		vencimento     string // vazio quando o boleto não tem vencimento
+		banco          string
+		segmento       string
+		empresa        string
+		valorRef       bool
+	}{
+		{
+			name:           "bancário pelo código de barras, ciclo de fator atual",
+			codigo:         "00196101000000123451110000000000000000000000",
+			referencia:     "2025-03-01",
+			tipo:           BoletoBancario,
+			codigoBarras:   "00196101000000123451110000000000000000000000",
+			linhaDigitavel: "00191110040000000000000000000000610100000012345",
+			valor:          123.45,
+			vencimento:     "2025-03-04",
+			banco:          "Banco do Brasil",
+		},
+		{
+			name:           "bancário pela linha digitável formatada",
+			codigo:         "00191.11004 00000.000000 00000.000000 6 10100000012345",
+			referencia:     "2025-03-01",
+			tipo:           BoletoBancario,
+			codigoBarras:   "00196101000000123451110000000000000000000000",
+			linhaDigitavel: "00191110040000000000000000000000610100000012345",
+			valor:          123.45,
+			vencimento:     "2025-03-04",
+			banco:          "Banco do Brasil",
+		},
+		{
+			name:           "bancário no ciclo de fator original",
+			codigo:         "34199876504321098765743210987657190000000100000",
+			referencia:     "2022-05-01",
+			tipo:           BoletoBancario,
+			codigoBarras:   "34191900000001000009876543210987654321098765",
+			linhaDigitavel: "34199876504321098765743210987657190000000100000",
+			valor:          1000,
+			vencimento:     "2022-05-29",
+			banco:          "Itaú Unibanco",
+		},
+		{
+			name:           "bancário sem vencimento (fator zerado)",
+			codigo:         "23795000000000500001234567890123456789012345",
+			referencia:     "2025-03-01",
+			tipo:           BoletoBancario,
+			codigoBarras:   "23795000000000500001234567890123456789012345",
+			linhaDigitavel: "23791234546789012345767890123457500000000050000",
+			valor:          500,
+			banco:          "Bradesco",
+		},
+		{
+			name:           "arrecadação de energia, módulo 10",
+			codigo:         "836900000016 234500481234 456789012345 567890123456",
+			referencia:     "2025-03-01",
+			tipo:           BoletoArrecadacao,
+			codigoBarras:   "83690000001234500481234567890123456789012345",
+			linhaDigitavel: "836900000016234500481234456789012345567890123456",
+			valor:          123.45,
+			segmento:       "3",
+			empresa:        "0048",
+		},
+		{
+			name:           "arrecadação segmento 6 com CNPJ, módulo 11",
+			codigo:         "86820000000999012345678123456789012345678901",
+			referencia:     "2025-03-01",
+			tipo:           BoletoArrecadacao,
+			codigoBarras:   "86820000000999012345678123456789012345678901",
+			linhaDigitavel: "868200000004999012345675812345678901123456789010",
+			valor:          99.90,
+			segmento:       "6",
+			empresa:        "12345678",
+		},
+		{
+			name:           "arrecadação com valor de referência",
+			codigo:         "857300000000010000010008000000000000000000000422",
+			referencia:     "2025-03-01",
+			tipo:           BoletoArrecadacao,
+			codigoBarras:   "85730000000010000010000000000000000000000042",
+			linhaDigitavel: "857300000000010000010008000000000000000000000422",
+			valor:          1,
+			segmento:       "5",
+			empresa:        "0001",
+			valorRef:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			referencia, _ := time.Parse("2006-01-02", tt.referencia)
+			boleto, err := ParseBoleto(tt.codigo, referencia)
+			if err != nil {
+				t.Fatalf("ParseBoleto(%q): %v", tt.codigo, err)
+			}
+			if boleto.Tipo != tt.tipo {
+				t.Errorf("tipo = %q, esperado %q", boleto.Tipo, tt.tipo)
+			}
+			if boleto.CodigoBarras != tt.codigoBarras {
+				t.Errorf("código de barras = %q, esperado %q", boleto.CodigoBarras, tt.codigoBarras)
+			}
+			if boleto.LinhaDigitavel != tt.linhaDigitavel {
+				t.Errorf("linha digitável = %q, esperado %q", boleto.LinhaDigitavel, tt.linhaDigitavel)
+			}
+			if boleto.Valor != tt.valor {
+				t.Errorf("valor = %v, esperado %v", boleto.Valor, tt.valor)
+			}
+			vencimento := ""
+			if boleto.Vencimento != nil {
+				vencimento = *boleto.Vencimento
+			}
+			if vencimento != tt.vencimento {
+				t.Errorf("vencimento = %q, esperado %q", vencimento, tt.vencimento)
+			}
+			if boleto.BancoNome != tt.banco {
+				t.Errorf("banco = %q, esperado %q", boleto.BancoNome, tt.banco)
+			}
+			if boleto.Segmento != tt.segmento {
+				t.Errorf("segmento = %q, esperado %q", boleto.Segmento, tt.segmento)
+			}
+			if tt.empresa != "" && boleto.Empresa != tt.empresa {
+				t.Errorf("empresa = %q, esperado %q", boleto.Empresa, tt.empresa)
+			}
+			if boleto.ValorReferencia != tt.valorRef {
+				t.Errorf("valor de referência = %v, esperado %v", boleto.ValorReferencia, tt.valorRef)
+			}
+		})
+	}
+}
+
+func TestParseBoletoInvalido(t *testing.T) {
+	tests := []struct {
+		name   string
+		codigo string
+	}{
+		{name: "vazio", codigo: ""},
+		{name: "tamanho inválido", codigo: "0019610100000012345"},
+		{name: "dígito geral do bancário", codigo: "00196101000000123451110000000000000000000001"},
+		{name: "dígito do campo 1 da linha", codigo: "00191110050000000000000000000000610100000012345"},
+		{name: "moeda diferente de real", codigo: "00186101000000123451110000000000000000000000"},
+		{name: "linha de 48 dígitos fora da arrecadação", codigo: "136900000016234500481234456789012345567890123456"},
+		{name: "dígito geral da arrecadação", codigo: "83680000001234500481234567890123456789012345"},
+		{name: "dígito do bloco da arrecadação", codigo: "836900000017234500481234456789012345567890123456"},
+		{name: "identificador de valor inválido", codigo: "83590000001234500481234567890123456789012345"},
+	}
+
+	referencia := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if boleto, err := ParseBoleto(tt.codigo, referencia); err == nil {
+				t.Fatalf("ParseBoleto(%q) = %+v, esperado erro", tt.codigo, boleto)
+			}
+		})
+	}
+}
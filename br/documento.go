@@ -0,0 +1,129 @@
+package br
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Tipos de documento
+const (
+	DocumentoCPF  = "cpf"
+	DocumentoCNPJ = "cnpj"
+)
+
+type Documento struct {
+	Tipo         string `json:"tipo"`
+	Numero       string `json:"numero"` // sem pontuação
+	Formatado    string `json:"formatado,omitempty"`
+	Valido       bool   `json:"valido"`
+	Alfanumerico bool   `json:"alfanumerico,omitempty"` // CNPJ no formato alfanumérico (a partir de 2026)
+	Motivo       string `json:"motivo,omitempty"`
+}
+
+// Unformat remove pontuação e espaços e converte letras para maiúsculas
+func Unformat(s string) string {
+	s = strings.ToUpper(s)
+	clean := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if (s[i] >= '0' && s[i] <= '9') || (s[i] >= 'A' && s[i] <= 'Z') {
+			clean = append(clean, s[i])
+		}
+	}
+	return string(clean)
+}
+
+func allSame(s string) bool {
+	return strings.Count(s, s[:1]) == len(s)
+}
+
+// cpfDV calcula um dígito do CPF com pesos decrescentes a partir de peso
+func cpfDV(digits string, peso int) byte {
+	sum := 0
+	for i := 0; i < len(digits); i++ {
+		sum += int(digits[i]-'0') * (peso - i)
+	}
+	resto := sum % 11
+	if resto < 2 {
+		return '0'
+	}
+	return byte('0' + 11 - resto)
+}
+
+// ValidateCPF confere tamanho e dígitos verificadores de um CPF, com ou sem pontuação
+func ValidateCPF(s string) Documento {
+	numero := Unformat(s)
+	doc := Documento{Tipo: DocumentoCPF, Numero: numero}
+
+	switch {
+	case len(numero) != 11:
+		doc.Motivo = fmt.Sprintf("CPF deve ter 11 dígitos, recebido %d", len(numero))
+	case onlyDigits(numero) != numero:
+		doc.Motivo = "CPF deve conter apenas dígitos"
+	case allSame(numero):
+		doc.Motivo = "CPF com todos os dígitos iguais"
+	case cpfDV(numero[:9], 10) != numero[9] || cpfDV(numero[:10], 11) != numero[10]:
+		doc.Motivo = "dígitos verificadores inválidos"
+	default:
+		doc.Valido = true
+		doc.Formatado = FormatCPF(numero)
+	}
+	return doc
+}
+
+// FormatCPF formata 11 dígitos como 000.000.000-00
+func FormatCPF(numero string) string {
+	numero = Unformat(numero)
+	if len(numero) != 11 {
+		return numero
+	}
+	return numero[0:3] + "." + numero[3:6] + "." + numero[6:9] + "-" + numero[9:11]
+}
+
+// cnpjDV calcula um dígito do CNPJ. Cada caractere vale seu código ASCII menos 48, o que mantém
+// o cálculo tradicional para dígitos e atende o CNPJ alfanumérico (A=17 ... Z=42).
+func cnpjDV(chars string) byte {
+	sum, peso := 0, 2
+	for i := len(chars) - 1; i >= 0; i-- {
+		sum += int(chars[i]-'0') * peso
+		peso++
+		if peso > 9 {
+			peso = 2
+		}
+	}
+	resto := sum % 11
+	if resto < 2 {
+		return '0'
+	}
+	return byte('0' + 11 - resto)
+}
+
+// ValidateCNPJ confere um CNPJ numérico ou alfanumérico (12 caracteres [0-9A-Z] e 2 dígitos verificadores)
+func ValidateCNPJ(s string) Documento {
+	numero := Unformat(s)
+	doc := Documento{Tipo: DocumentoCNPJ, Numero: numero}
+
+	switch {
+	case len(numero) != 14:
+		doc.Motivo = fmt.Sprintf("CNPJ deve ter 14 caracteres, recebido %d", len(numero))
+	case onlyDigits(numero[12:]) != numero[12:]:
+		doc.Motivo = "os dígitos verificadores do CNPJ devem ser numéricos"
+	case allSame(numero):
+		doc.Motivo = "CNPJ com todos os caracteres iguais"
+	case cnpjDV(numero[:12]) != numero[12] || cnpjDV(numero[:13]) != numero[13]:
+		doc.Motivo = "dígitos verificadores inválidos"
+	default:
+		doc.Valido = true
+		doc.Formatado = FormatCNPJ(numero)
+	}
+	doc.Alfanumerico = onlyDigits(numero) != numero
+	return doc
+}
+
+// FormatCNPJ formata 14 caracteres como 00.000.000/0000-00
+func FormatCNPJ(numero string) string {
+	numero = Unformat(numero)
+	if len(numero) != 14 {
+		return numero
+	}
+	return numero[0:2] + "." + numero[2:5] + "." + numero[5:8] + "/" + numero[8:12] + "-" + numero[12:14]
+}
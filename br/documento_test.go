@@ -0,0 +1,111 @@
+package br
+
+import "testing"
+
+func TestValidateCPF(t *testing.T) {
+	tests := []struct {
+		name      string
+		entrada   string
+		valido    bool
+		numero    string
+		formatado string
+	}{
+		{name: "formatado", entrada: "529.982.247-25", valido: true, numero: "52998224725", formatado: "529.982.247-25"},
+		{name: "só dígitos", entrada: "11144477735", valido: true, numero: "11144477735", formatado: "111.444.777-35"},
+		{name: "com espaços", entrada: " 111 444 777 35 ", valido: true, numero: "11144477735", formatado: "111.444.777-35"},
+		{name: "primeiro dígito errado", entrada: "529.982.247-35", numero: "52998224735"},
+		{name: "segundo dígito errado", entrada: "529.982.247-24", numero: "52998224724"},
+		{name: "todos os dígitos iguais", entrada: "111.111.111-11", numero: "11111111111"},
+		{name: "curto", entrada: "5299822472", numero: "5299822472"},
+		{name: "longo", entrada: "529982247250", numero: "529982247250"},
+		{name: "com letra", entrada: "529.982.247-2A", numero: "5299822472A"},
+		{name: "vazio", entrada: "", numero: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := ValidateCPF(tt.entrada)
+			if doc.Valido != tt.valido {
+				t.Fatalf("ValidateCPF(%q).Valido = %v, esperado %v (motivo: %s)", tt.entrada, doc.Valido, tt.valido, doc.Motivo)
+			}
+			if doc.Tipo != DocumentoCPF || doc.Numero != tt.numero || doc.Formatado != tt.formatado {
+				t.Errorf("ValidateCPF(%q) = %+v", tt.entrada, doc)
+			}
+			if !doc.Valido && doc.Motivo == "" {
+				t.Errorf("ValidateCPF(%q) inválido sem motivo", tt.entrada)
+			}
+		})
+	}
+}
+
+func TestValidateCNPJ(t *testing.T) {
+	tests := []struct {
+		name         string
+		entrada      string
+		valido       bool
+		alfanumerico bool
+		numero       string
+		formatado    string
+	}{
+		{name: "numérico formatado", entrada: "11.222.333/0001-81", valido: true, numero: "11222333000181", formatado: "11.222.333/0001-81"},
+		{name: "numérico só dígitos", entrada: "11222333000181", valido: true, numero: "11222333000181", formatado: "11.222.333/0001-81"},
+		{name: "alfanumérico formatado", entrada: "12.ABC.345/01DE-35", valido: true, alfanumerico: true, numero: "12ABC34501DE35", formatado: "12.ABC.345/01DE-35"},
+		{name: "alfanumérico em minúsculas", entrada: "12.abc.345/01de-35", valido: true, alfanumerico: true, numero: "12ABC34501DE35", formatado: "12.ABC.345/01DE-35"},
+		{name: "numérico com dígito errado", entrada: "11.222.333/0001-80", numero: "11222333000180"},
+		{name: "alfanumérico com dígito errado", entrada: "12.ABC.345/01DE-36", alfanumerico: true, numero: "12ABC34501DE36"},
+		{name: "letra trocada no alfanumérico", entrada: "12.ABD.345/01DE-35", alfanumerico: true, numero: "12ABD34501DE35"},
+		{name: "dígito verificador com letra", entrada: "12.ABC.345/01DE-3A", alfanumerico: true, numero: "12ABC34501DE3A"},
+		{name: "todos iguais", entrada: "00.000.000/0000-00", numero: "00000000000000"},
+		{name: "curto", entrada: "11.222.333/0001", numero: "112223330001"},
+		{name: "vazio", entrada: "", numero: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := ValidateCNPJ(tt.entrada)
+			if doc.Valido != tt.valido {
+				t.Fatalf("ValidateCNPJ(%q).Valido = %v, esperado %v (motivo: %s)", tt.entrada, doc.Valido, tt.valido, doc.Motivo)
+			}
+			if doc.Tipo != DocumentoCNPJ || doc.Numero != tt.numero || doc.Formatado != tt.formatado || doc.Alfanumerico != tt.alfanumerico {
+				t.Errorf("ValidateCNPJ(%q) = %+v", tt.entrada, doc)
+			}
+			if !doc.Valido && doc.Motivo == "" {
+				t.Errorf("ValidateCNPJ(%q) inválido sem motivo", tt.entrada)
+			}
+		})
+	}
+}
+
+func TestPixKeyType(t *testing.T) {
+	tests := []struct {
+		chave string
+		tipo  string // vazio quando a chave é inválida
+	}{
+		{chave: "52998224725", tipo: ChaveCPF},
+		{chave: "52998224724"},
+		{chave: "11222333000181", tipo: ChaveCNPJ},
+		{chave: "12ABC34501DE35", tipo: ChaveCNPJ},
+		{chave: "12ABC34501DE36"},
+		{chave: "fulano@email.com", tipo: ChaveEmail},
+		{chave: "fulano@email"},
+		{chave: "+5511999999999", tipo: ChaveTelefone},
+		{chave: "+55119"},
+		{chave: "123e4567-e12b-12d1-a456-426655440000", tipo: ChaveEVP},
+		{chave: "qualquer-coisa"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.chave, func(t *testing.T) {
+			tipo, err := PixKeyType(tt.chave)
+			if tt.tipo == "" {
+				if err == nil {
+					t.Fatalf("PixKeyType(%q) = %q, esperado erro", tt.chave, tipo)
+				}
+				return
+			}
+			if err != nil || tipo != tt.tipo {
+				t.Fatalf("PixKeyType(%q) = %q, %v; esperado %q", tt.chave, tipo, err, tt.tipo)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package br
+
+import "time"
+
+// Feriados de data fixa sem pregão na B3 (mês e dia). Desde 2022 a B3 abre nos feriados
+// municipais de São Paulo; o 20 de novembro virou feriado nacional em 2024
+var feriadosFixosB3 = map[[2]int]string{
+	{1, 1}:   "Confraternização Universal",
+	{4, 21}:  "Tiradentes",
+	{5, 1}:   "Dia do Trabalho",
+	{9, 7}:   "Independência",
+	{10, 12}: "Nossa Senhora Aparecida",
+	{11, 2}:  "Finados",
+	{11, 15}: "Proclamação da República",
+	{12, 24}: "Véspera de Natal",
+	{12, 25}: "Natal",
+	{12, 31}: "Último dia do ano",
+}
+
+// Pascoa calcula o domingo de Páscoa do ano (algoritmo de Meeus/Jones/Butcher)
+func Pascoa(ano int) time.Time {
+	a := ano % 19
+	b := ano / 100
+	c := ano % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	mes := (h + l - 7*m + 114) / 31
+	dia := (h+l-7*m+114)%31 + 1
+	return time.Date(ano, time.Month(mes), dia, 0, 0, 0, 0, time.UTC)
+}
+
+// FeriadoB3 retorna o nome do feriado quando não há pregão na data
+func FeriadoB3(t time.Time) (string, bool) {
+	if nome, ok := feriadosFixosB3[[2]int{int(t.Month()), t.Day()}]; ok {
+		return nome, true
+	}
+	if t.Month() == time.November && t.Day() == 20 && t.Year() >= 2024 {
+		return "Dia da Consciência Negra", true
+	}
+
+	data := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	pascoa := Pascoa(t.Year())
+	switch data {
+	case pascoa.AddDate(0, 0, -48), pascoa.AddDate(0, 0, -47):
+		return "Carnaval", true
+	case pascoa.AddDate(0, 0, -2):
+		return "Sexta-feira Santa", true
+	case pascoa.AddDate(0, 0, 60):
+		return "Corpus Christi", true
+	}
+	return "", false
+}
+
+// DiaDePregao indica se a B3 abre na data: dias úteis que não são feriados
+func DiaDePregao(t time.Time) bool {
+	if t.Weekday() == time.Saturday || t.Weekday() == time.Sunday {
+		return false
+	}
+	_, feriado := FeriadoB3(t)
+	return !feriado
+}
@@ -0,0 +1,61 @@
+package br
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPascoa(t *testing.T) {
+	tests := map[int]string{
+		2019: "2019-04-21",
+		2024: "2024-03-31",
+		2025: "2025-04-20",
+		2026: "2026-04-05",
+		2038: "2038-04-25",
+	}
+	for ano, want := range tests {
+		if got := Pascoa(ano).Format("2006-01-02"); got != want {
+			t.Errorf("Pascoa(%d) = %s, esperado %s", ano, got, want)
+		}
+	}
+}
+
+func TestDiaDePregao(t *testing.T) {
+	tests := []struct {
+		data    string
+		pregao  bool
+		feriado string
+	}{
+		{data: "2025-01-01", feriado: "Confraternização Universal"},
+		{data: "2025-01-02", pregao: true},
+		{data: "2025-03-03", feriado: "Carnaval"},
+		{data: "2025-03-04", feriado: "Carnaval"},
+		{data: "2025-03-05", pregao: true}, // Quarta-feira de Cinzas abre à tarde
+		{data: "2025-04-18", feriado: "Sexta-feira Santa"},
+		{data: "2025-04-21", feriado: "Tiradentes"},
+		{data: "2025-06-19", feriado: "Corpus Christi"},
+		{data: "2025-07-09", pregao: true}, // feriado municipal de São Paulo
+		{data: "2023-11-20", pregao: true},
+		{data: "2025-11-20", feriado: "Dia da Consciência Negra"},
+		{data: "2025-12-24", feriado: "Véspera de Natal"},
+		{data: "2025-12-31", feriado: "Último dia do ano"},
+		{data: "2026-02-16", feriado: "Carnaval"},
+		{data: "2025-03-08"}, // sábado
+		{data: "2025-03-09"}, // domingo
+	}
+
+	saoPaulo := time.FixedZone("BRT", -3*60*60)
+	for _, tt := range tests {
+		t.Run(tt.data, func(t *testing.T) {
+			data, _ := time.ParseInLocation("2006-01-02", tt.data, saoPaulo)
+			data = data.Add(18*time.Hour + 30*time.Minute)
+			if got := DiaDePregao(data); got != tt.pregao {
+				t.Errorf("DiaDePregao(%s) = %v, esperado %v", tt.data, got, tt.pregao)
+			}
+			nome, _ := FeriadoB3(data)
+			if nome != tt.feriado {
+				t.Errorf("FeriadoB3(%s) = %q, esperado %q", tt.data, nome, tt.feriado)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package br
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// onlyDigits remove pontuação e espaços, mantendo apenas os dígitos
+func onlyDigits(s string) string {
+	digits := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if isDigit(s[i]) {
+			digits = append(digits, s[i])
+		}
+	}
+	return string(digits)
+}
+
+// mod10 calcula o dígito verificador módulo 10 (pesos 2 e 1 a partir da direita)
+func mod10(digits string) int {
+	sum, weight := 0, 2
+	for i := len(digits) - 1; i >= 0; i-- {
+		product := int(digits[i]-'0') * weight
+		sum += product/10 + product%10
+		weight = 3 - weight
+	}
+	return (10 - sum%10) % 10
+}
+
+// mod11Sum soma os dígitos com pesos de 2 a 9 a partir da direita
+func mod11Sum(digits string) int {
+	sum, weight := 0, 2
+	for i := len(digits) - 1; i >= 0; i-- {
+		sum += int(digits[i]-'0') * weight
+		weight++
+		if weight > 9 {
+			weight = 2
+		}
+	}
+	return sum
+}
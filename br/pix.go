@@ -0,0 +1,280 @@
+package br
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// IDs dos campos do BR Code (EMV QRCPS-MPM) usados pelo Pix
+const (
+	pixPayloadFormat   = "00"
+	pixInitiation      = "01"
+	pixMerchantAccount = "26"
+	pixCategoryCode    = "52"
+	pixCurrency        = "53"
+	pixAmount          = "54"
+	pixCountry         = "58"
+	pixMerchantName    = "59"
+	pixMerchantCity    = "60"
+	pixAdditionalData  = "62"
+	pixCRC             = "63"
+
+	pixGUI         = "br.gov.bcb.pix"
+	pixMaxNome     = 25
+	pixMaxCidade   = 15
+	pixMaxTxID     = 25
+	pixMaxChave    = 77
+	pixTxIDDefault = "***"
+)
+
+// Tipos de chave Pix
+const (
+	ChaveCPF      = "cpf"
+	ChaveCNPJ     = "cnpj"
+	ChaveEmail    = "email"
+	ChaveTelefone = "telefone"
+	ChaveEVP      = "evp" // chave aleatória
+)
+
+var (
+	pixTxIDPattern     = regexp.MustCompile(`^[A-Za-z0-9]{1,25}$`)
+	pixTelefonePattern = regexp.MustCompile(`^\+[1-9][0-9]{10,13}$`)
+	pixEmailPattern    = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+	pixEVPPattern      = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
+
+// Pix descreve um pagamento Pix codificado em BR Code
+type Pix struct {
+	Chave     string  `json:"chave,omitempty"`
+	TipoChave string  `json:"tipo_chave,omitempty"` // cpf, cnpj, email, telefone ou evp
+	URL       string  `json:"url,omitempty"`        // location do Pix dinâmico
+	Descricao string  `json:"descricao,omitempty"`
+	Valor     float64 `json:"valor,omitempty"` // zero quando o pagador informa o valor
+	Nome      string  `json:"nome"`
+	Cidade    string  `json:"cidade"`
+	TxID      string  `json:"txid,omitempty"`
+	Estatico  bool    `json:"estatico"`
+	UnicoUso  bool    `json:"unico_uso,omitempty"`
+}
+
+// CRC16 calcula o CRC-16/CCITT-FALSE (polinômio 0x1021, valor inicial 0xFFFF)
+func CRC16(data string) uint16 {
+	crc := uint16(0xFFFF)
+	for i := 0; i < len(data); i++ {
+		crc ^= uint16(data[i]) << 8
+		for bit := 0; bit < 8; bit++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+func tlv(id, value string) string {
+	return fmt.Sprintf("%s%02d%s", id, len(value), value)
+}
+
+// pixText remove acentos e caracteres fora do ASCII imprimível e limita o tamanho
+func pixText(s string, max int) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	if folded, _, err := transform.String(t, s); err == nil {
+		s = folded
+	}
+	s = strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7E {
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(s))
+	if max <= 0 {
+		return ""
+	}
+	if len(s) > max {
+		s = strings.TrimSpace(s[:max])
+	}
+	return s
+}
+
+// PixKeyType identifica o tipo da chave Pix, validando os dígitos de CPF e CNPJ
+func PixKeyType(chave string) (string, error) {
+	switch {
+	case strings.Contains(chave, "@"):
+		if !pixEmailPattern.MatchString(chave) {
+			return "", fmt.Errorf("chave de e-mail inválida")
+		}
+		return ChaveEmail, nil
+	case strings.HasPrefix(chave, "+"):
+		if !pixTelefonePattern.MatchString(chave) {
+			return "", fmt.Errorf("chave de telefone deve estar no formato +5511999999999")
+		}
+		return ChaveTelefone, nil
+	case pixEVPPattern.MatchString(chave):
+		return ChaveEVP, nil
+	case len(chave) == 11:
+		if doc := ValidateCPF(chave); !doc.Valido {
+			return "", fmt.Errorf("chave CPF inválida: %s", doc.Motivo)
+		}
+		return ChaveCPF, nil
+	case len(chave) == 14:
+		if doc := ValidateCNPJ(chave); !doc.Valido {
+			return "", fmt.Errorf("chave CNPJ inválida: %s", doc.Motivo)
+		}
+		return ChaveCNPJ, nil
+	default:
+		return "", fmt.Errorf("chave Pix não reconhecida (use CPF, CNPJ, e-mail, telefone ou chave aleatória)")
+	}
+}
+
+// GeneratePix monta o "Pix copia e cola" de um QR Code estático
+func GeneratePix(p Pix) (string, error) {
+	chave := strings.TrimSpace(p.Chave)
+	if chave == "" || len(chave) > pixMaxChave {
+		return "", fmt.Errorf("chave deve ter entre 1 e %d caracteres", pixMaxChave)
+	}
+	// CPF e CNPJ vão no payload sem pontuação
+	if doc := Unformat(chave); !strings.ContainsAny(chave, "@+") && (len(doc) == 11 || len(doc) == 14) && len(doc) != len(chave) {
+		chave = doc
+	}
+	if _, err := PixKeyType(chave); err != nil {
+		return "", err
+	}
+	nome := pixText(p.Nome, pixMaxNome)
+	cidade := pixText(p.Cidade, pixMaxCidade)
+	if nome == "" || cidade == "" {
+		return "", fmt.Errorf("nome e cidade do recebedor são obrigatórios")
+	}
+	if p.Valor < 0 {
+		return "", fmt.Errorf("valor não pode ser negativo")
+	}
+	txid := p.TxID
+	if txid == "" {
+		txid = pixTxIDDefault
+	} else if !pixTxIDPattern.MatchString(txid) {
+		return "", fmt.Errorf("txid deve ter até %d letras ou números", pixMaxTxID)
+	}
+
+	// O campo 26 tem no máximo 99 caracteres; com chaves longas não sobra espaço para a descrição
+	conta := tlv("00", pixGUI) + tlv("01", chave)
+	if espaco := 99 - len(conta) - 4; espaco > 0 {
+		if descricao := pixText(p.Descricao, espaco); descricao != "" {
+			conta += tlv("02", descricao)
+		}
+	}
+
+	var payload strings.Builder
+	payload.WriteString(tlv(pixPayloadFormat, "01"))
+	payload.WriteString(tlv(pixMerchantAccount, conta))
+	payload.WriteString(tlv(pixCategoryCode, "0000"))
+	payload.WriteString(tlv(pixCurrency, "986"))
+	if p.Valor > 0 {
+		payload.WriteString(tlv(pixAmount, strconv.FormatFloat(p.Valor, 'f', 2, 64)))
+	}
+	payload.WriteString(tlv(pixCountry, "BR"))
+	payload.WriteString(tlv(pixMerchantName, nome))
+	payload.WriteString(tlv(pixMerchantCity, cidade))
+	payload.WriteString(tlv(pixAdditionalData, tlv("05", txid)))
+	payload.WriteString(pixCRC + "04")
+
+	return payload.String() + fmt.Sprintf("%04X", CRC16(payload.String())), nil
+}
+
+// parseTLV separa os campos de um nível do BR Code
+func parseTLV(data string) (map[string]string, []string, error) {
+	fields := make(map[string]string)
+	var order []string
+	for i := 0; i < len(data); {
+		if i+4 > len(data) {
+			return nil, nil, fmt.Errorf("campo truncado na posição %d", i)
+		}
+		id := data[i : i+2]
+		// O tamanho são exatamente dois dígitos; Atoi aceitaria sinais como "-1" ou "+5"
+		if !isDigit(data[i+2]) || !isDigit(data[i+3]) {
+			return nil, nil, fmt.Errorf("tamanho inválido no campo %s", id)
+		}
+		length, err := strconv.Atoi(data[i+2 : i+4])
+		if err != nil || length < 0 || i+4+length > len(data) {
+			return nil, nil, fmt.Errorf("tamanho inválido no campo %s", id)
+		}
+		fields[id] = data[i+4 : i+4+length]
+		order = append(order, id)
+		i += 4 + length
+	}
+	return fields, order, nil
+}
+
+// DecodePix interpreta e valida um "Pix copia e cola" (estático ou dinâmico), conferindo o CRC
+func DecodePix(payload string) (*Pix, error) {
+	payload = strings.TrimSpace(payload)
+	fields, order, err := parseTLV(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if fields[pixPayloadFormat] != "01" || len(order) == 0 || order[0] != pixPayloadFormat {
+		return nil, fmt.Errorf("payload deve começar com o campo 00 igual a 01")
+	}
+	crc, ok := fields[pixCRC]
+	if !ok || order[len(order)-1] != pixCRC || len(crc) != 4 {
+		return nil, fmt.Errorf("campo 63 (CRC) ausente ou fora do final do payload")
+	}
+	if expected := fmt.Sprintf("%04X", CRC16(payload[:len(payload)-4])); !strings.EqualFold(crc, expected) {
+		return nil, fmt.Errorf("CRC inválido: esperado %s, recebido %s", expected, crc)
+	}
+	if fields[pixCurrency] != "986" {
+		return nil, fmt.Errorf("moeda %q não suportada (esperado 986, real)", fields[pixCurrency])
+	}
+
+	conta, _, err := parseTLV(fields[pixMerchantAccount])
+	if err != nil {
+		return nil, fmt.Errorf("campo 26 inválido: %w", err)
+	}
+	if !strings.EqualFold(conta["00"], pixGUI) {
+		return nil, fmt.Errorf("campo 26 não contém o identificador %s", pixGUI)
+	}
+
+	pix := &Pix{
+		Chave:     conta["01"],
+		Descricao: conta["02"],
+		URL:       conta["25"],
+		Nome:      fields[pixMerchantName],
+		Cidade:    fields[pixMerchantCity],
+		Estatico:  conta["25"] == "",
+		UnicoUso:  fields[pixInitiation] == "12",
+	}
+	if pix.Chave == "" && pix.URL == "" {
+		return nil, fmt.Errorf("campo 26 sem chave nem URL")
+	}
+	if pix.Chave != "" {
+		pix.TipoChave, _ = PixKeyType(pix.Chave)
+	}
+
+	if amount, ok := fields[pixAmount]; ok {
+		valor, err := strconv.ParseFloat(amount, 64)
+		if err != nil || valor < 0 {
+			return nil, fmt.Errorf("valor inválido: %q", amount)
+		}
+		pix.Valor = valor
+	}
+
+	if adicional, ok := fields[pixAdditionalData]; ok {
+		extra, _, err := parseTLV(adicional)
+		if err != nil {
+			return nil, fmt.Errorf("campo 62 inválido: %w", err)
+		}
+		if txid := extra["05"]; txid != pixTxIDDefault {
+			pix.TxID = txid
+		}
+	}
+
+	return pix, nil
+}
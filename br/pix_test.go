@@ -0,0 +1,150 @@
+package br
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// withCRC fecha o payload com o campo 63 e o CRC calculado
+func withCRC(payload string) string {
+	payload += pixCRC + "04"
+	return payload + fmt.Sprintf("%04X", CRC16(payload))
+}
+
+func TestDecodePixExemploBCB(t *testing.T) {
+	// Exemplo de Pix estático do manual do BR Code do Banco Central
+	payload := "00020126580014br.gov.bcb.pix0136123e4567-e12b-12d1-a456-4266554400005204000053039865802BR5913Fulano de Tal6008BRASILIA62070503***63041D3D"
+
+	pix, err := DecodePix(payload)
+	if err != nil {
+		t.Fatalf("DecodePix: %v", err)
+	}
+	if pix.Chave != "123e4567-e12b-12d1-a456-426655440000" || pix.TipoChave != ChaveEVP {
+		t.Errorf("chave = %q (%s), esperado a chave aleatória do exemplo", pix.Chave, pix.TipoChave)
+	}
+	if pix.Nome != "Fulano de Tal" || pix.Cidade != "BRASILIA" || !pix.Estatico || pix.TxID != "" || pix.Valor != 0 {
+		t.Errorf("DecodePix = %+v", pix)
+	}
+}
+
+func TestPixRoundTrip(t *testing.T) {
+	emailLongo := strings.Repeat("a", 64) + "@exemplo.com" // 76 caracteres: não sobra espaço para a descrição
+
+	tests := []struct {
+		name string
+		pix  Pix
+		want Pix // campos esperados após decodificar
+	}{
+		{
+			name: "e-mail com valor, txid e descrição acentuada",
+			pix:  Pix{Chave: "fulano@email.com", Valor: 25.5, Nome: "Fulano de Tal", Cidade: "São Paulo", TxID: "RACHA01", Descricao: "Racha do almoço"},
+			want: Pix{Chave: "fulano@email.com", TipoChave: ChaveEmail, Valor: 25.5, Nome: "Fulano de Tal", Cidade: "Sao Paulo", TxID: "RACHA01", Descricao: "Racha do almoco", Estatico: true},
+		},
+		{
+			name: "telefone sem valor",
+			pix:  Pix{Chave: "+5511999999999", Nome: "Beltrana", Cidade: "Recife"},
+			want: Pix{Chave: "+5511999999999", TipoChave: ChaveTelefone, Nome: "Beltrana", Cidade: "Recife", Estatico: true},
+		},
+		{
+			name: "CPF formatado vai sem pontuação",
+			pix:  Pix{Chave: "529.982.247-25", Valor: 10, Nome: "Ciclano", Cidade: "Curitiba"},
+			want: Pix{Chave: "52998224725", TipoChave: ChaveCPF, Valor: 10, Nome: "Ciclano", Cidade: "Curitiba", Estatico: true},
+		},
+		{
+			name: "CNPJ alfanumérico",
+			pix:  Pix{Chave: "12.ABC.345/01DE-35", Valor: 1999.99, Nome: "Loja Exemplo", Cidade: "Belo Horizonte"},
+			want: Pix{Chave: "12ABC34501DE35", TipoChave: ChaveCNPJ, Valor: 1999.99, Nome: "Loja Exemplo", Cidade: "Belo Horizonte", Estatico: true},
+		},
+		{
+			name: "chave aleatória e nome e cidade truncados",
+			pix:  Pix{Chave: "123e4567-e12b-12d1-a456-426655440000", Nome: "Fulano de Tal da Silva Sauro Junior", Cidade: "Sao Jose dos Campos"},
+			want: Pix{Chave: "123e4567-e12b-12d1-a456-426655440000", TipoChave: ChaveEVP, Nome: "Fulano de Tal da Silva Sa", Cidade: "Sao Jose dos Ca", Estatico: true},
+		},
+		{
+			name: "chave longa descarta a descrição",
+			pix:  Pix{Chave: emailLongo, Nome: "Fulano", Cidade: "Natal", Descricao: "Não cabe no campo 26"},
+			want: Pix{Chave: emailLongo, TipoChave: ChaveEmail, Nome: "Fulano", Cidade: "Natal", Estatico: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, err := GeneratePix(tt.pix)
+			if err != nil {
+				t.Fatalf("GeneratePix: %v", err)
+			}
+			got, err := DecodePix(payload)
+			if err != nil {
+				t.Fatalf("DecodePix(%q): %v", payload, err)
+			}
+			if *got != tt.want {
+				t.Errorf("DecodePix(GeneratePix()) = %+v, esperado %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGeneratePixInvalido(t *testing.T) {
+	tests := []struct {
+		name string
+		pix  Pix
+	}{
+		{name: "sem chave", pix: Pix{Nome: "Fulano", Cidade: "Natal"}},
+		{name: "chave longa demais", pix: Pix{Chave: strings.Repeat("a", 70) + "@exemplo.com", Nome: "Fulano", Cidade: "Natal"}},
+		{name: "CPF com dígito errado", pix: Pix{Chave: "52998224724", Nome: "Fulano", Cidade: "Natal"}},
+		{name: "telefone com formatação", pix: Pix{Chave: "+55 (11) 99999-9999", Nome: "Fulano", Cidade: "Natal"}},
+		{name: "sem nome", pix: Pix{Chave: "fulano@email.com", Cidade: "Natal"}},
+		{name: "valor negativo", pix: Pix{Chave: "fulano@email.com", Nome: "Fulano", Cidade: "Natal", Valor: -1}},
+		{name: "txid com símbolos", pix: Pix{Chave: "fulano@email.com", Nome: "Fulano", Cidade: "Natal", TxID: "racha-01"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if payload, err := GeneratePix(tt.pix); err == nil {
+				t.Fatalf("GeneratePix(%+v) = %q, esperado erro", tt.pix, payload)
+			}
+		})
+	}
+}
+
+func TestDecodePixMalformado(t *testing.T) {
+	conta := tlv("00", pixGUI) + tlv("01", "fulano@email.com")
+	resto := tlv(pixCategoryCode, "0000") + tlv(pixCurrency, "986") + tlv(pixCountry, "BR") +
+		tlv(pixMerchantName, "Fulano") + tlv(pixMerchantCity, "Natal")
+	valido := withCRC(tlv(pixPayloadFormat, "01") + tlv(pixMerchantAccount, conta) + resto)
+
+	if _, err := DecodePix(valido); err != nil {
+		t.Fatalf("payload base deveria ser válido: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		payload string
+	}{
+		{name: "vazio", payload: ""},
+		{name: "tamanho com sinal", payload: "00-1abcd6304ABCD"},
+		{name: "tamanho com sinal de mais", payload: "00+1a6304ABCD"},
+		{name: "campo truncado", payload: "000201260"},
+		{name: "tamanho além do fim", payload: "00020126990014br.gov.bcb.pix"},
+		{name: "CRC errado", payload: valido[:len(valido)-4] + "0000"},
+		{name: "sem CRC", payload: tlv(pixPayloadFormat, "01") + tlv(pixMerchantAccount, conta) + resto},
+		{name: "CRC fora do final", payload: tlv(pixPayloadFormat, "01") + "6304ABCD" + tlv(pixMerchantAccount, conta)},
+		{name: "não começa pelo campo 00", payload: withCRC(tlv(pixMerchantAccount, conta) + tlv(pixPayloadFormat, "01") + resto)},
+		{name: "moeda diferente de real", payload: withCRC(tlv(pixPayloadFormat, "01") + tlv(pixMerchantAccount, conta) + strings.Replace(resto, "5303986", "5303840", 1))},
+		{name: "campo 26 sem o GUI do Pix", payload: withCRC(tlv(pixPayloadFormat, "01") + tlv(pixMerchantAccount, tlv("00", "br.gov.outro")+tlv("01", "fulano@email.com")) + resto)},
+		{name: "campo 26 sem chave nem URL", payload: withCRC(tlv(pixPayloadFormat, "01") + tlv(pixMerchantAccount, tlv("00", pixGUI)) + resto)},
+		{name: "campo 26 com tamanho interno com sinal", payload: withCRC(tlv(pixPayloadFormat, "01") + tlv(pixMerchantAccount, "00-1"+pixGUI) + resto)},
+		{name: "valor negativo", payload: withCRC(tlv(pixPayloadFormat, "01") + tlv(pixMerchantAccount, conta) + tlv(pixAmount, "-1.00") + resto)},
+		{name: "valor não numérico", payload: withCRC(tlv(pixPayloadFormat, "01") + tlv(pixMerchantAccount, conta) + tlv(pixAmount, "abc") + resto)},
+		{name: "campo 62 malformado", payload: withCRC(tlv(pixPayloadFormat, "01") + tlv(pixMerchantAccount, conta) + resto + tlv(pixAdditionalData, "05"))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if pix, err := DecodePix(tt.payload); err == nil {
+				t.Fatalf("DecodePix(%q) = %+v, esperado erro", tt.payload, pix)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package chatstore
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"go-br-finance-api/models"
+)
+
+// CachedStore usa um store durável (Postgres) como fonte da verdade e outro (Redis) como cache
+// das conversas ativas. Falhas no cache são registradas e ignoradas, então o chat continua
+// funcionando com o Redis fora do ar.
+type CachedStore struct {
+	cache   Store
+	durable Store
+}
+
+func NewCachedStore(cache, durable Store) *CachedStore {
+	return &CachedStore{cache: cache, durable: durable}
+}
+
+func (s *CachedStore) Load(ctx context.Context, sessionID string) (models.Conversation, bool, error) {
+	conversation, found, err := s.cache.Load(ctx, sessionID)
+	if err != nil {
+		log.Println("⚠️  Erro ao ler conversa do cache:", err)
+	}
+	if err == nil && found {
+		return conversation, true, nil
+	}
+
+	conversation, found, err = s.durable.Load(ctx, sessionID)
+	if err != nil || !found {
+		return conversation, found, err
+	}
+
+	if err := s.cache.Save(ctx, conversation); err != nil {
+		log.Println("⚠️  Erro ao gravar conversa no cache:", err)
+	}
+	return conversation, true, nil
+}
+
+func (s *CachedStore) Save(ctx context.Context, conversation models.Conversation) error {
+	if err := s.durable.Save(ctx, conversation); err != nil {
+		if errors.Is(err, ErrConflict) {
+			// O cache pode estar desatualizado; a próxima leitura busca a conversa no store durável
+			if err := s.cache.Delete(ctx, conversation); err != nil {
+				log.Println("⚠️  Erro ao remover conversa do cache:", err)
+			}
+		}
+		return err
+	}
+	if err := s.cache.Save(ctx, conversation); err != nil {
+		log.Println("⚠️  Erro ao gravar conversa no cache:", err)
+	}
+	return nil
+}
+
+func (s *CachedStore) Delete(ctx context.Context, conversation models.Conversation) error {
+	if err := s.durable.Delete(ctx, conversation); err != nil {
+		return err
+	}
+	if err := s.cache.Delete(ctx, conversation); err != nil {
+		log.Println("⚠️  Erro ao remover conversa do cache:", err)
+	}
+	return nil
+}
+
+// List consulta o store durável, que tem todas as sessões
+func (s *CachedStore) List(ctx context.Context, userID string) ([]models.ChatSession, error) {
+	return s.durable.List(ctx, userID)
+}
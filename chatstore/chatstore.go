@@ -0,0 +1,33 @@
+package chatstore
+
+import (
+	"context"
+	"errors"
+
+	"go-br-finance-api/models"
+)
+
+// ErrConflict indica que outra requisição gravou mensagens na sessão depois que ela foi carregada
+var ErrConflict = errors.New("conversa alterada por outra requisição")
+
+// Store guarda as conversas do chat
+type Store interface {
+	// Load retorna a conversa da sessão; found é false quando ela não existe
+	Load(ctx context.Context, sessionID string) (conversation models.Conversation, found bool, err error)
+	// Save grava a conversa inteira, incluindo título, resumo e mensagens novas; retorna ErrConflict
+	// quando o store não tem mais as StoredMessages mensagens com que a conversa foi carregada
+	Save(ctx context.Context, conversation models.Conversation) error
+	Delete(ctx context.Context, conversation models.Conversation) error
+	// List retorna as sessões do usuário, da atualizada mais recentemente para a mais antiga
+	List(ctx context.Context, userID string) ([]models.ChatSession, error)
+}
+
+func sessionOf(conversation models.Conversation) models.ChatSession {
+	return models.ChatSession{
+		SessionID:    conversation.SessionID,
+		Title:        conversation.Title,
+		CreatedAt:    conversation.CreatedAt,
+		UpdatedAt:    conversation.UpdatedAt,
+		MessageCount: len(conversation.Messages),
+	}
+}
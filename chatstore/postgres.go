@@ -0,0 +1,143 @@
+package chatstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"go-br-finance-api/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	conversaColumns = `session_id, user_id, title, summary, summarized_count,
+		to_char(created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"') AS created_at,
+		to_char(updated_at AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"') AS updated_at`
+	mensagemColumns = `role, content, model, tokens, prompt_tokens,
+		to_char(created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"') AS created_at`
+)
+
+// Conversas sem mensagens há mais do que o TTL ($2, em segundos; 0 = sem expiração)
+const activeCondition = `($2::float8 = 0 OR updated_at > CURRENT_TIMESTAMP - make_interval(secs => $2::float8))`
+
+// PostgresStore guarda as conversas em chat_conversas e cada mensagem em chat_mensagens,
+// com data, modelo e tokens, mantendo o histórico completo para consulta e análise
+type PostgresStore struct {
+	db  *sqlx.DB
+	ttl time.Duration
+}
+
+// NewPostgresStore cria o store. Conversas sem mensagens há mais de ttl deixam de ser carregadas e
+// listadas, como se não existissem, mas continuam no banco para análise; ttl 0 nunca expira
+func NewPostgresStore(db *sqlx.DB, ttl time.Duration) *PostgresStore {
+	return &PostgresStore{db: db, ttl: ttl}
+}
+
+func (s *PostgresStore) Load(ctx context.Context, sessionID string) (models.Conversation, bool, error) {
+	var conversation models.Conversation
+	err := s.db.GetContext(ctx, &conversation, "SELECT "+conversaColumns+" FROM chat_conversas WHERE session_id = $1 AND "+activeCondition,
+		sessionID, s.ttl.Seconds())
+	if err == sql.ErrNoRows {
+		return conversation, false, nil
+	}
+	if err != nil {
+		return conversation, false, err
+	}
+
+	conversation.Messages = []models.Message{}
+	err = s.db.SelectContext(ctx, &conversation.Messages, "SELECT "+mensagemColumns+" FROM chat_mensagens WHERE session_id = $1 ORDER BY posicao", sessionID)
+	conversation.StoredMessages = len(conversation.Messages)
+	return conversation, err == nil, err
+}
+
+// Save atualiza a conversa e insere apenas as mensagens que ainda não estão gravadas. O upsert
+// bloqueia a linha da conversa até o commit, então duas gravações da mesma sessão são serializadas
+// e a segunda encontra mais mensagens do que as carregadas e retorna ErrConflict
+func (s *PostgresStore) Save(ctx context.Context, conversation models.Conversation) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if conversation.StoredMessages == 0 && s.ttl > 0 {
+		if err := s.archiveExpired(ctx, tx, conversation.SessionID); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO chat_conversas (session_id, user_id, title, summary, summarized_count, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, COALESCE(NULLIF($6, '')::timestamptz, CURRENT_TIMESTAMP), COALESCE(NULLIF($7, '')::timestamptz, CURRENT_TIMESTAMP))
+		ON CONFLICT (session_id) DO UPDATE SET user_id = EXCLUDED.user_id, title = EXCLUDED.title, summary = EXCLUDED.summary,
+			summarized_count = EXCLUDED.summarized_count, updated_at = EXCLUDED.updated_at`,
+		conversation.SessionID, conversation.UserID, conversation.Title, conversation.Summary, conversation.SummarizedCount,
+		conversation.CreatedAt, conversation.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	var stored int
+	if err := tx.GetContext(ctx, &stored, "SELECT COUNT(*) FROM chat_mensagens WHERE session_id = $1", conversation.SessionID); err != nil {
+		return err
+	}
+	if stored != conversation.StoredMessages {
+		return ErrConflict
+	}
+
+	for i := stored; i < len(conversation.Messages); i++ {
+		msg := conversation.Messages[i]
+		_, err := tx.ExecContext(ctx, `INSERT INTO chat_mensagens (session_id, posicao, role, content, model, tokens, prompt_tokens, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, COALESCE(NULLIF($8, '')::timestamptz, CURRENT_TIMESTAMP))`,
+			conversation.SessionID, i, msg.Role, msg.Content, msg.Model, msg.Tokens, msg.PromptTokens, msg.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// archiveExpired move uma conversa expirada com o mesmo id para outro id, mantendo as mensagens
+// para análise, para que o id possa começar uma conversa nova
+func (s *PostgresStore) archiveExpired(ctx context.Context, tx *sqlx.Tx, sessionID string) error {
+	var expired bool
+	err := tx.GetContext(ctx, &expired, "SELECT NOT "+activeCondition+" FROM chat_conversas WHERE session_id = $1 FOR UPDATE",
+		sessionID, s.ttl.Seconds())
+	if err == sql.ErrNoRows || (err == nil && !expired) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	archivedID := fmt.Sprintf("expirada:%d:%s", time.Now().UnixNano(), sessionID)
+	_, err = tx.ExecContext(ctx, `INSERT INTO chat_conversas (session_id, user_id, title, summary, summarized_count, created_at, updated_at)
+		SELECT $1, user_id, title, summary, summarized_count, created_at, updated_at FROM chat_conversas WHERE session_id = $2`,
+		archivedID, sessionID)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE chat_mensagens SET session_id = $1 WHERE session_id = $2", archivedID, sessionID); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, "DELETE FROM chat_conversas WHERE session_id = $1", sessionID)
+	return err
+}
+
+func (s *PostgresStore) Delete(ctx context.Context, conversation models.Conversation) error {
+	_, err := s.db.ExecContext(ctx, "DELETE FROM chat_conversas WHERE session_id = $1", conversation.SessionID)
+	return err
+}
+
+func (s *PostgresStore) List(ctx context.Context, userID string) ([]models.ChatSession, error) {
+	sessions := []models.ChatSession{}
+	query := `SELECT c.session_id, c.title,
+			to_char(c.created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"') AS created_at,
+			to_char(c.updated_at AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"') AS updated_at,
+			(SELECT COUNT(*) FROM chat_mensagens m WHERE m.session_id = c.session_id) AS message_count
+		FROM chat_conversas c WHERE c.user_id = $1 AND ` + activeCondition + ` ORDER BY c.updated_at DESC`
+	err := s.db.SelectContext(ctx, &sessions, query, userID, s.ttl.Seconds())
+	return sessions, err
+}
@@ -0,0 +1,143 @@
+package chatstore
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"go-br-finance-api/models"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStore guarda cada conversa como JSON em chat:<session_id>, com TTL renovado a cada gravação,
+// e indexa as sessões de cada usuário em um sorted set pontuado pela última atualização
+type RedisStore struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// NewRedisStore cria o store; ttl 0 mantém as conversas para sempre
+func NewRedisStore(client *redis.Client, ttl time.Duration) *RedisStore {
+	return &RedisStore{client: client, ttl: ttl}
+}
+
+func sessionKey(sessionID string) string {
+	return "chat:" + sessionID
+}
+
+func userSessionsKey(userID string) string {
+	return "chat:user:" + userID + ":sessions"
+}
+
+func (s *RedisStore) Load(ctx context.Context, sessionID string) (models.Conversation, bool, error) {
+	var conversation models.Conversation
+
+	conversationJSON, err := s.client.Get(ctx, sessionKey(sessionID)).Result()
+	if err == redis.Nil {
+		return conversation, false, nil
+	}
+	if err != nil {
+		return conversation, false, err
+	}
+
+	err = json.Unmarshal([]byte(conversationJSON), &conversation)
+	conversation.StoredMessages = len(conversation.Messages)
+	return conversation, err == nil, err
+}
+
+func (s *RedisStore) Save(ctx context.Context, conversation models.Conversation) error {
+	conversationJSON, err := json.Marshal(conversation)
+	if err != nil {
+		return err
+	}
+
+	updatedAt, _ := time.Parse(time.RFC3339, conversation.UpdatedAt)
+	userKey := userSessionsKey(conversation.UserID)
+
+	pipe := s.client.TxPipeline()
+	pipe.Set(ctx, sessionKey(conversation.SessionID), conversationJSON, s.ttl)
+	pipe.ZAdd(ctx, userKey, redis.Z{Score: float64(updatedAt.Unix()), Member: conversation.SessionID})
+	if s.ttl > 0 {
+		pipe.Expire(ctx, userKey, s.ttl)
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (s *RedisStore) Delete(ctx context.Context, conversation models.Conversation) error {
+	pipe := s.client.TxPipeline()
+	pipe.Del(ctx, sessionKey(conversation.SessionID))
+	pipe.ZRem(ctx, userSessionsKey(conversation.UserID), conversation.SessionID)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// List também remove do índice as sessões que já expiraram
+func (s *RedisStore) List(ctx context.Context, userID string) ([]models.ChatSession, error) {
+	sessions := []models.ChatSession{}
+
+	userKey := userSessionsKey(userID)
+	sessionIDs, err := s.client.ZRevRange(ctx, userKey, 0, -1).Result()
+	if err != nil || len(sessionIDs) == 0 {
+		return sessions, err
+	}
+
+	keys := make([]string, len(sessionIDs))
+	for i, id := range sessionIDs {
+		keys[i] = sessionKey(id)
+	}
+	values, err := s.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var expired []interface{}
+	for i, value := range values {
+		conversationJSON, ok := value.(string)
+		if !ok {
+			expired = append(expired, sessionIDs[i])
+			continue
+		}
+
+		var conversation models.Conversation
+		if err := json.Unmarshal([]byte(conversationJSON), &conversation); err != nil || conversation.UserID != userID {
+			continue
+		}
+		sessions = append(sessions, sessionOf(conversation))
+	}
+
+	if len(expired) > 0 {
+		s.client.ZRem(ctx, userKey, expired...)
+	}
+	return sessions, nil
+}
+
+// DropOwnerless remove as conversas gravadas antes de as sessões terem dono, que não expiravam
+// e não podem mais ser lidas por ninguém
+func (s *RedisStore) DropOwnerless(ctx context.Context) (int, error) {
+	removed := 0
+	iter := s.client.Scan(ctx, 0, "chat:*", 500).Iterator()
+	for iter.Next(ctx) {
+		key := iter.Val()
+		if strings.HasPrefix(key, "chat:user:") {
+			continue
+		}
+
+		conversationJSON, err := s.client.Get(ctx, key).Result()
+		if err != nil {
+			continue
+		}
+		var conversation models.Conversation
+		if json.Unmarshal([]byte(conversationJSON), &conversation) == nil && conversation.UserID != "" {
+			continue
+		}
+
+		if err := s.client.Del(ctx, key).Err(); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, iter.Err()
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"go-br-finance-api/chatstore"
+)
+
+var ChatStore chatstore.Store
+
+// ConnectChatStore guarda as conversas do chat no Postgres, usando o Redis (quando conectado)
+// como cache das conversas ativas. Conversas sem mensagens há mais de CHAT_SESSION_TTL expiram:
+// somem do cache e deixam de ser carregadas e listadas, mas continuam no Postgres para análise
+func ConnectChatStore() {
+	ttl := 24 * time.Hour
+	if v, err := time.ParseDuration(os.Getenv("CHAT_SESSION_TTL")); err == nil && v >= 0 {
+		ttl = v
+	}
+
+	postgres := chatstore.NewPostgresStore(DB, ttl)
+	if RedisClient == nil {
+		ChatStore = postgres
+		log.Println("⚠️  Conversas do chat sem cache: Redis não conectado")
+		return
+	}
+
+	redisStore := chatstore.NewRedisStore(RedisClient, ttl)
+	go func() {
+		removed, err := redisStore.DropOwnerless(context.Background())
+		if err != nil {
+			log.Println("⚠️  Erro ao remover conversas antigas sem dono:", err)
+		} else if removed > 0 {
+			log.Printf("✅ %d conversas antigas sem dono removidas do Redis", removed)
+		}
+	}()
+
+	ChatStore = chatstore.NewCachedStore(redisStore, postgres)
+	log.Println("✅ Conversas do chat no Postgres com cache no Redis")
+}
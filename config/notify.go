@@ -0,0 +1,20 @@
+package config
+
+import (
+	"log"
+
+	"go-br-finance-api/notify"
+)
+
+var Notifier notify.Sender
+
+func ConnectNotifier() {
+	sender, err := notify.NewSenderFromEnv()
+	if err != nil {
+		log.Println("⚠️  Notificações desativadas:", err)
+		return
+	}
+
+	Notifier = sender
+	log.Println("✅ Notificações configuradas")
+}
@@ -0,0 +1,24 @@
+package config
+
+import (
+	"log"
+
+	"go-br-finance-api/websearch"
+)
+
+var SearchProvider websearch.SearchProvider
+
+func ConnectSearchProvider() {
+	provider, err := websearch.NewProviderFromEnv()
+	if err != nil {
+		log.Println("⚠️  Busca na web desativada:", err)
+		return
+	}
+	if provider == nil {
+		log.Println("⚠️  Busca na web desativada: WEB_SEARCH_PROVIDER não configurado")
+		return
+	}
+
+	SearchProvider = provider
+	log.Println("✅ Busca na web configurada")
+}
@@ -16,3 +16,283 @@ WHERE NOT EXISTS (SELECT 1 FROM recomendacoes_financeiras WHERE titulo = 'Onde i
 INSERT INTO recomendacoes_financeiras (titulo, descricao)
 SELECT 'Qual melhor corretora hoje', 'XP Investimentos'
 WHERE NOT EXISTS (SELECT 1 FROM recomendacoes_financeiras WHERE titulo = 'Qual melhor corretora hoje');
+
+-- Indicadores fundamentalistas por empresa, um registro por trimestre
+CREATE TABLE IF NOT EXISTS fundamentos (
+    id SERIAL PRIMARY KEY,
+    symbol TEXT NOT NULL,
+    ano INT NOT NULL,
+    trimestre INT NOT NULL,
+    pl DOUBLE PRECISION,
+    pvp DOUBLE PRECISION,
+    roe DOUBLE PRECISION,
+    margem_liquida DOUBLE PRECISION,
+    divida_liquida_ebitda DOUBLE PRECISION,
+    dividend_yield DOUBLE PRECISION,
+    ev_ebitda DOUBLE PRECISION,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (symbol, ano, trimestre)
+);
+-- Números dos demonstrativos trimestrais (DRE e balanço), em reais
+ALTER TABLE fundamentos ADD COLUMN IF NOT EXISTS receita_liquida DOUBLE PRECISION;
+ALTER TABLE fundamentos ADD COLUMN IF NOT EXISTS lucro_liquido DOUBLE PRECISION;
+ALTER TABLE fundamentos ADD COLUMN IF NOT EXISTS patrimonio_liquido DOUBLE PRECISION;
+
+-- Última cotação conhecida de cada ação, usada pelo screener
+CREATE TABLE IF NOT EXISTS cotacoes (
+    symbol TEXT PRIMARY KEY,
+    name TEXT NOT NULL DEFAULT '',
+    sector TEXT NOT NULL DEFAULT '',
+    close DOUBLE PRECISION NOT NULL DEFAULT 0,
+    change DOUBLE PRECISION NOT NULL DEFAULT 0,
+    volume BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Carteira: transações de compra e venda por usuário
+CREATE TABLE IF NOT EXISTS portfolio_transacoes (
+    id SERIAL PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    tipo_ativo TEXT NOT NULL,
+    symbol TEXT NOT NULL,
+    operacao TEXT NOT NULL,
+    quantidade DOUBLE PRECISION NOT NULL,
+    preco DOUBLE PRECISION NOT NULL,
+    taxas DOUBLE PRECISION NOT NULL DEFAULT 0,
+    data DATE NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_portfolio_transacoes_user ON portfolio_transacoes (user_id, data);
+
+-- Importação de extratos: deduplicação por hash do lançamento
+ALTER TABLE portfolio_transacoes ADD COLUMN IF NOT EXISTS import_hash TEXT;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_portfolio_transacoes_import ON portfolio_transacoes (user_id, import_hash);
+
+-- Proventos recebidos (dividendos, JCP e rendimentos de FII)
+CREATE TABLE IF NOT EXISTS portfolio_proventos (
+    id SERIAL PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    tipo TEXT NOT NULL,
+    tipo_ativo TEXT NOT NULL,
+    symbol TEXT NOT NULL,
+    valor DOUBLE PRECISION NOT NULL,
+    data DATE NOT NULL,
+    instituicao TEXT NOT NULL DEFAULT '',
+    import_hash TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_portfolio_proventos_import ON portfolio_proventos (user_id, import_hash);
+
+-- Alertas de preço com entrega via webhook
+CREATE TABLE IF NOT EXISTS alertas (
+    id SERIAL PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    tipo TEXT NOT NULL,
+    symbol TEXT NOT NULL,
+    condicao TEXT NOT NULL,
+    valor DOUBLE PRECISION NOT NULL,
+    webhook_url TEXT NOT NULL,
+    segredo TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'ativo',
+    valor_disparo DOUBLE PRECISION,
+    disparado_em TIMESTAMP,
+    entregue_em TIMESTAMP,
+    tentativas INT NOT NULL DEFAULT 0,
+    ultimo_erro TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_alertas_status ON alertas (status);
+-- Próxima tentativa de entrega do webhook, feita pelo worker de alertas
+ALTER TABLE alertas ADD COLUMN IF NOT EXISTS proximo_envio TIMESTAMP;
+-- Alertas disparados antes desta coluna e nunca tentados voltam para a fila de entrega
+UPDATE alertas SET proximo_envio = disparado_em
+WHERE status = 'disparado' AND entregue_em IS NULL AND proximo_envio IS NULL AND tentativas = 0 AND ultimo_erro = '';
+
+-- Dispositivos do app para notificações push
+CREATE TABLE IF NOT EXISTS dispositivos (
+    id SERIAL PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    token TEXT NOT NULL UNIQUE,
+    plataforma TEXT NOT NULL DEFAULT 'android',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_dispositivos_user ON dispositivos (user_id);
+
+-- Tópicos de notificação assinados por usuário
+CREATE TABLE IF NOT EXISTS notificacao_topicos (
+    user_id TEXT NOT NULL,
+    topico TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, topico)
+);
+
+-- Resumo diário do mercado, gerado após o fechamento
+CREATE TABLE IF NOT EXISTS resumos_diarios (
+    data DATE PRIMARY KEY,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Reserva da geração de cada resumo, para que só uma réplica da API gere e notifique
+CREATE TABLE IF NOT EXISTS resumos_execucoes (
+    data DATE PRIMARY KEY,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Orçamento pessoal: categorias, limites mensais, lançamentos e recorrências
+CREATE TABLE IF NOT EXISTS orcamento_categorias (
+    id SERIAL PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    nome TEXT NOT NULL,
+    tipo TEXT NOT NULL DEFAULT 'despesa',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, nome)
+);
+
+CREATE TABLE IF NOT EXISTS orcamento_limites (
+    categoria_id INT NOT NULL REFERENCES orcamento_categorias(id) ON DELETE CASCADE,
+    mes TEXT NOT NULL,
+    valor DOUBLE PRECISION NOT NULL,
+    PRIMARY KEY (categoria_id, mes)
+);
+
+CREATE TABLE IF NOT EXISTS orcamento_recorrencias (
+    id SERIAL PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    categoria_id INT REFERENCES orcamento_categorias(id) ON DELETE SET NULL,
+    descricao TEXT NOT NULL,
+    tipo TEXT NOT NULL,
+    valor DOUBLE PRECISION NOT NULL,
+    dia INT NOT NULL,
+    inicio DATE NOT NULL,
+    fim DATE,
+    gerado_ate DATE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS orcamento_lancamentos (
+    id SERIAL PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    categoria_id INT REFERENCES orcamento_categorias(id) ON DELETE SET NULL,
+    descricao TEXT NOT NULL,
+    tipo TEXT NOT NULL,
+    valor DOUBLE PRECISION NOT NULL,
+    data DATE NOT NULL,
+    recorrencia_id INT REFERENCES orcamento_recorrencias(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_orcamento_lancamentos_user_data ON orcamento_lancamentos (user_id, data);
+
+-- Importação de extratos bancários: identificador da transação e regras de categorização
+ALTER TABLE orcamento_lancamentos ADD COLUMN IF NOT EXISTS fitid TEXT;
+-- O FITID só é único dentro de cada conta (BANKID/ACCTID do OFX)
+ALTER TABLE orcamento_lancamentos ADD COLUMN IF NOT EXISTS conta TEXT NOT NULL DEFAULT '';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_orcamento_lancamentos_conta_fitid ON orcamento_lancamentos (user_id, conta, fitid);
+
+CREATE TABLE IF NOT EXISTS orcamento_regras (
+    id SERIAL PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    padrao TEXT NOT NULL,
+    categoria_id INT NOT NULL REFERENCES orcamento_categorias(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Artigos educacionais curados, usados como base de conhecimento do chat
+CREATE TABLE IF NOT EXISTS artigos (
+    id SERIAL PRIMARY KEY,
+    titulo TEXT NOT NULL,
+    conteudo TEXT NOT NULL,
+    url TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO artigos (titulo, conteudo)
+SELECT 'Reserva de emergência', 'A reserva de emergência deve cobrir de 6 a 12 meses do custo de vida e ficar em aplicações de baixo risco e liquidez diária, como Tesouro Selic ou CDBs com liquidez diária que rendam perto de 100% do CDI. Ela vem antes de investimentos de maior risco, como ações.'
+WHERE NOT EXISTS (SELECT 1 FROM artigos WHERE titulo = 'Reserva de emergência');
+
+INSERT INTO artigos (titulo, conteudo)
+SELECT 'Tesouro Direto: Selic, Prefixado e IPCA+', 'O Tesouro Selic acompanha a taxa básica de juros e tem pouca oscilação, sendo indicado para reserva e curto prazo. O Prefixado garante uma taxa fixa até o vencimento, mas oscila se vendido antes. O Tesouro IPCA+ paga a inflação mais uma taxa real e protege o poder de compra no longo prazo. Há cobrança de IR regressivo de 22,5% a 15% sobre o rendimento.'
+WHERE NOT EXISTS (SELECT 1 FROM artigos WHERE titulo = 'Tesouro Direto: Selic, Prefixado e IPCA+');
+
+INSERT INTO artigos (titulo, conteudo)
+SELECT 'Fundos imobiliários (FIIs)', 'FIIs são negociados na bolsa e distribuem rendimentos geralmente mensais, isentos de IR para pessoa física em fundos com mais de 100 cotistas e cotas negociadas em bolsa. O ganho de capital na venda de cotas é tributado em 20%. Avalie vacância, qualidade dos imóveis ou recebíveis, gestão e P/VP antes de investir.'
+WHERE NOT EXISTS (SELECT 1 FROM artigos WHERE titulo = 'Fundos imobiliários (FIIs)');
+
+INSERT INTO artigos (titulo, conteudo)
+SELECT 'Diversificação e perfil de investidor', 'Diversificar entre renda fixa, ações, FIIs e ativos no exterior reduz o risco da carteira. A proporção de cada classe depende do perfil (conservador, moderado ou arrojado), do prazo e dos objetivos. Rebalancear periodicamente mantém a alocação próxima do alvo.'
+WHERE NOT EXISTS (SELECT 1 FROM artigos WHERE titulo = 'Diversificação e perfil de investidor');
+
+-- Embeddings dos trechos de recomendações e artigos (busca por similaridade de cosseno na aplicação)
+CREATE TABLE IF NOT EXISTS conteudo_embeddings (
+    id SERIAL PRIMARY KEY,
+    fonte TEXT NOT NULL,
+    fonte_id INT NOT NULL,
+    trecho_indice INT NOT NULL,
+    titulo TEXT NOT NULL,
+    trecho TEXT NOT NULL,
+    conteudo_hash TEXT NOT NULL,
+    modelo TEXT NOT NULL,
+    embedding DOUBLE PRECISION[] NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (fonte, fonte_id, trecho_indice)
+);
+
+-- Perfis de prompt do chat, editáveis por administradores
+CREATE TABLE IF NOT EXISTS chat_perfis (
+    id SERIAL PRIMARY KEY,
+    nome TEXT NOT NULL UNIQUE,
+    descricao TEXT NOT NULL DEFAULT '',
+    system_prompt TEXT NOT NULL,
+    modelo TEXT,
+    temperatura DOUBLE PRECISION,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE chat_perfis ADD COLUMN IF NOT EXISTS max_tokens INT;
+ALTER TABLE chat_perfis ADD COLUMN IF NOT EXISTS context_window INT;
+
+INSERT INTO chat_perfis (nome, descricao, system_prompt, temperatura)
+VALUES ('conservador', 'Prioriza segurança, liquidez e renda fixa', 'Você é um consultor financeiro brasileiro de perfil conservador. Priorize preservação de capital, reserva de emergência e renda fixa, e destaque os riscos de qualquer investimento de renda variável. Mantenha as respostas concisas, com no máximo 300 caracteres. Responda sempre em português brasileiro, nunca em inglês.', 0.1)
+ON CONFLICT (nome) DO NOTHING;
+
+INSERT INTO chat_perfis (nome, descricao, system_prompt, temperatura)
+VALUES ('educacional', 'Explica conceitos de forma didática, sem recomendar produtos', 'Você é um educador financeiro brasileiro. Explique os conceitos de forma didática, com exemplos simples, sem recomendar produtos ou ativos específicos. Responda em até 600 caracteres, sempre em português brasileiro, nunca em inglês.', 0.4)
+ON CONFLICT (nome) DO NOTHING;
+
+-- Conversas do chat (o Redis guarda só as conversas ativas, como cache)
+CREATE TABLE IF NOT EXISTS chat_conversas (
+    session_id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    title TEXT NOT NULL DEFAULT '',
+    summary TEXT NOT NULL DEFAULT '',
+    summarized_count INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_chat_conversas_user ON chat_conversas (user_id, updated_at DESC);
+
+-- Mensagens do chat, com o modelo e os tokens de cada resposta
+CREATE TABLE IF NOT EXISTS chat_mensagens (
+    id SERIAL PRIMARY KEY,
+    session_id TEXT NOT NULL REFERENCES chat_conversas(session_id) ON DELETE CASCADE,
+    posicao INT NOT NULL,
+    role TEXT NOT NULL,
+    content TEXT NOT NULL,
+    model TEXT NOT NULL DEFAULT '',
+    tokens INT NOT NULL DEFAULT 0,
+    prompt_tokens INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (session_id, posicao)
+);
+
+CREATE INDEX IF NOT EXISTS idx_chat_mensagens_created_at ON chat_mensagens (created_at);
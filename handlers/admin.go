@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+)
+
+// adminErrors are the error key and messages of an admin guard, so each group of endpoints
+// keeps the error shape of its own handlers
+type adminErrors struct {
+	key      string
+	disabled string
+	missing  string
+	invalid  string
+}
+
+var (
+	adminErrorsPT = adminErrors{
+		key:      "erro",
+		disabled: "Administração desabilitada: ADMIN_TOKEN não configurado",
+		missing:  "Cabeçalho X-Admin-Token é obrigatório",
+		invalid:  "Token de administrador inválido",
+	}
+	adminErrorsChat = adminErrors{
+		key:      "error",
+		disabled: "Administration disabled: ADMIN_TOKEN is not set",
+		missing:  "X-Admin-Token header is required",
+		invalid:  "Invalid admin token",
+	}
+)
+
+// RequireAdmin only lets the request through when the X-Admin-Token header matches ADMIN_TOKEN.
+// Without ADMIN_TOKEN configured, admin endpoints are disabled.
+func RequireAdmin() gin.HandlerFunc {
+	return requireAdminToken(adminErrorsPT)
+}
+
+// RequireChatAdmin is RequireAdmin for the chat endpoints, which answer errors as {"error": ...}
+func RequireChatAdmin() gin.HandlerFunc {
+	return requireAdminToken(adminErrorsChat)
+}
+
+func requireAdminToken(errs adminErrors) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		adminToken := os.Getenv("ADMIN_TOKEN")
+		if adminToken == "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{errs.key: errs.disabled})
+			return
+		}
+
+		token := c.GetHeader("X-Admin-Token")
+		if token == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{errs.key: errs.missing})
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1 {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{errs.key: errs.invalid})
+			return
+		}
+
+		c.Next()
+	}
+}
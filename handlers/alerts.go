@@ -0,0 +1,381 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-br-finance-api/config"
+	"go-br-finance-api/models"
+	"go-br-finance-api/notify"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Tentativas de entrega do webhook. A primeira sai assim que o alerta dispara; as demais são feitas
+// pelo worker de alertas com espera exponencial, a partir do estado gravado, e sobrevivem a reinícios
+const maxWebhookAttempts = 5
+
+// Tempo reservado para uma entrega em andamento, durante o qual o alerta não é pego por outra execução
+const webhookDeliveryLease = 5 * time.Minute
+
+type CreateAlertRequest struct {
+	Tipo       string  `json:"tipo" binding:"required"`     // acao ou cambio
+	Symbol     string  `json:"symbol" binding:"required"`   // ex: PETR4 ou USD
+	Condicao   string  `json:"condicao" binding:"required"` // acima ou abaixo
+	Valor      float64 `json:"valor" binding:"required"`
+	WebhookURL string  `json:"webhook_url" binding:"required"`
+}
+
+type AlertWebhookPayload struct {
+	AlertaID    int       `json:"alerta_id"`
+	Tipo        string    `json:"tipo"`
+	Symbol      string    `json:"symbol"`
+	Condicao    string    `json:"condicao"`
+	Valor       float64   `json:"valor"`
+	ValorAtual  float64   `json:"valor_atual"`
+	DisparadoEm time.Time `json:"disparado_em"`
+}
+
+// signWebhook assina "timestamp.corpo" com HMAC-SHA256 usando o segredo do alerta
+func signWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookRetryDelay é a espera antes da próxima tentativa após a tentativa informada (2, 4, 8 e 16 minutos)
+func webhookRetryDelay(attempt int) time.Duration {
+	return time.Duration(1<<attempt) * time.Minute
+}
+
+// deliverWebhook faz uma tentativa de entrega do payload assinado e grava o resultado; em caso de falha
+// agenda a próxima tentativa, que o worker de alertas fará
+func deliverWebhook(alerta models.Alerta) {
+	payload := AlertWebhookPayload{
+		AlertaID: alerta.ID,
+		Tipo:     alerta.Tipo,
+		Symbol:   alerta.Symbol,
+		Condicao: alerta.Condicao,
+		Valor:    alerta.Valor,
+	}
+	if alerta.ValorDisparo != nil {
+		payload.ValorAtual = *alerta.ValorDisparo
+	}
+	if alerta.DisparadoEm != nil {
+		if disparadoEm, err := time.Parse(time.RFC3339Nano, *alerta.DisparadoEm); err == nil {
+			payload.DisparadoEm = disparadoEm
+		}
+	}
+	body, _ := json.Marshal(payload)
+
+	attempt := alerta.Tentativas + 1
+	err := sendWebhook(alerta, body)
+	if err == nil {
+		config.DB.Exec("UPDATE alertas SET entregue_em = CURRENT_TIMESTAMP, tentativas = $1, ultimo_erro = '', proximo_envio = NULL WHERE id = $2", attempt, alerta.ID)
+		return
+	}
+
+	if attempt >= maxWebhookAttempts || errors.Is(err, errWebhookDestino) {
+		// Desiste: sem tentativas restantes, ou o host passou a resolver para um endereço interno
+		config.DB.Exec("UPDATE alertas SET tentativas = $1, ultimo_erro = $2, proximo_envio = NULL WHERE id = $3", attempt, err.Error(), alerta.ID)
+		log.Printf("⚠️  Falha ao entregar alerta %d: %v", alerta.ID, err)
+		return
+	}
+	config.DB.Exec("UPDATE alertas SET tentativas = $1, ultimo_erro = $2, proximo_envio = CURRENT_TIMESTAMP + make_interval(secs => $3) WHERE id = $4",
+		attempt, err.Error(), webhookRetryDelay(attempt).Seconds(), alerta.ID)
+}
+
+func sendWebhook(alerta models.Alerta, body []byte) error {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req, err := http.NewRequest("POST", alerta.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Alert-Timestamp", timestamp)
+	req.Header.Set("X-Alert-Signature", signWebhook(alerta.Segredo, timestamp, body))
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook retornou status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// deliverPendingWebhooks entrega os alertas disparados cujo webhook ainda não foi entregue e cuja
+// próxima tentativa já venceu. Cada alerta é reservado antes do envio, para que execuções
+// concorrentes (ou outra instância) não o entreguem duas vezes
+func deliverPendingWebhooks() error {
+	var alertas []models.Alerta
+	err := config.DB.Select(&alertas, `UPDATE alertas SET proximo_envio = CURRENT_TIMESTAMP + make_interval(secs => $1)
+		WHERE id IN (
+			SELECT id FROM alertas
+			WHERE status = $2 AND entregue_em IS NULL AND tentativas < $3
+				AND proximo_envio IS NOT NULL AND proximo_envio <= CURRENT_TIMESTAMP
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`, webhookDeliveryLease.Seconds(), models.AlertaDisparado, maxWebhookAttempts)
+	if err != nil {
+		return err
+	}
+
+	for _, alerta := range alertas {
+		go deliverWebhook(alerta)
+	}
+	return nil
+}
+
+// alertTriggered verifica se o valor atual satisfaz a condição do alerta
+func alertTriggered(alerta models.Alerta, atual float64) bool {
+	if alerta.Condicao == "acima" {
+		return atual >= alerta.Valor
+	}
+	return atual <= alerta.Valor
+}
+
+// checkAlerts avalia os alertas ativos contra as cotações em cache e o câmbio e entrega os webhooks pendentes
+func checkAlerts(ctx context.Context) error {
+	if err := evaluateAlerts(ctx); err != nil {
+		return err
+	}
+	return deliverPendingWebhooks()
+}
+
+// evaluateAlerts marca como disparados os alertas ativos cuja condição foi atingida
+func evaluateAlerts(ctx context.Context) error {
+	var alertas []models.Alerta
+	if err := config.DB.Select(&alertas, "SELECT * FROM alertas WHERE status = $1", models.AlertaAtivo); err != nil {
+		return err
+	}
+	if len(alertas) == 0 {
+		return nil
+	}
+
+	// Cotações das ações: lista em cache e, para os demais ativos, uma consulta agrupada
+	prices := make(map[string]float64)
+	if stocks, err := loadStocks(ctx); err == nil {
+		for _, stock := range stocks {
+			prices[stock.Symbol] = stock.Price
+		}
+	}
+	var missing []string
+	for _, a := range alertas {
+		if _, found := prices[a.Symbol]; a.Tipo == models.AlertaAcao && !found {
+			missing = append(missing, a.Symbol)
+		}
+	}
+	if len(missing) > 0 {
+		if quotes, err := fetchQuotes(missing); err == nil {
+			for _, q := range quotes {
+				prices[q.Symbol] = q.Price
+			}
+		}
+	}
+
+	for _, alerta := range alertas {
+		var atual float64
+		if alerta.Tipo == models.AlertaCambio {
+			rate, err := fetchExchangeRate(alerta.Symbol)
+			if err != nil {
+				continue
+			}
+			atual = rate
+		} else {
+			price, found := prices[alerta.Symbol]
+			if !found || price <= 0 {
+				continue
+			}
+			atual = price
+		}
+
+		if !alertTriggered(alerta, atual) {
+			continue
+		}
+
+		// Marca como disparado com a entrega do webhook pendente; deliverPendingWebhooks faz o envio
+		result, err := config.DB.Exec("UPDATE alertas SET status = $1, valor_disparo = $2, disparado_em = CURRENT_TIMESTAMP, proximo_envio = CURRENT_TIMESTAMP WHERE id = $3 AND status = $4",
+			models.AlertaDisparado, atual, alerta.ID, models.AlertaAtivo)
+		if err != nil {
+			continue
+		}
+		if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+			continue
+		}
+
+		go notifyUser(alerta.UserID, models.TopicoAlertas, notify.Notification{
+			Title: "Alerta disparado: " + alerta.Symbol,
+			Body:  fmt.Sprintf("%s está %s de %.2f (atual: %.2f)", alerta.Symbol, alerta.Condicao, alerta.Valor, atual),
+			Data:  map[string]string{"tipo": "alerta", "alerta_id": strconv.Itoa(alerta.ID)},
+		})
+	}
+
+	return nil
+}
+
+// StartAlertWorker avalia os alertas periodicamente em segundo plano
+func StartAlertWorker(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := checkAlerts(context.Background()); err != nil {
+				log.Println("⚠️  Erro ao avaliar alertas:", err)
+			}
+		}
+	}()
+}
+
+// CreateAlert godoc
+// @Summary Criar alerta de preço
+// @Description Registra um alerta como "PETR4 acima de R$ 40" ou "USD abaixo de 5,0". Quando disparado, o payload é enviado ao webhook assinado com HMAC-SHA256 (cabeçalhos X-Alert-Timestamp e X-Alert-Signature sobre "timestamp.corpo"). O segredo é retornado apenas na criação
+// @Tags alerts
+// @Accept  json
+// @Produce  json
+// @Param X-User-ID header string true "Identificador do usuário"
+// @Param request body CreateAlertRequest true "Dados do alerta"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /alerts [post]
+func CreateAlert(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req CreateAlertRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Dados inválidos", "detalhes": err.Error()})
+		return
+	}
+
+	req.Tipo = strings.ToLower(strings.TrimSpace(req.Tipo))
+	req.Condicao = strings.ToLower(strings.TrimSpace(req.Condicao))
+	req.Symbol = strings.ToUpper(strings.TrimSpace(req.Symbol))
+
+	if req.Tipo != models.AlertaAcao && req.Tipo != models.AlertaCambio {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "tipo deve ser acao ou cambio"})
+		return
+	}
+	if req.Condicao != "acima" && req.Condicao != "abaixo" {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "condicao deve ser acima ou abaixo"})
+		return
+	}
+	if req.Valor <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "valor deve ser positivo"})
+		return
+	}
+	if req.Tipo == models.AlertaCambio {
+		// Aceita "USD" ou "USD/BRL"
+		req.Symbol = strings.TrimSuffix(strings.TrimSuffix(req.Symbol, "BRL"), "/")
+	}
+	if req.Tipo == models.AlertaAcao && !validTicker(req.Symbol) {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "symbol inválido"})
+		return
+	}
+	if req.Tipo == models.AlertaCambio && !validCurrency(req.Symbol) {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "symbol deve ser o código da moeda com três letras, ex: USD"})
+		return
+	}
+
+	if err := validateWebhookURL(c.Request.Context(), req.WebhookURL); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": err.Error()})
+		return
+	}
+
+	secretBytes := make([]byte, 32)
+	if _, err := rand.Read(secretBytes); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao gerar segredo do webhook"})
+		return
+	}
+	secret := hex.EncodeToString(secretBytes)
+
+	query := `INSERT INTO alertas (user_id, tipo, symbol, condicao, valor, webhook_url, segredo)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
+	var id int
+	err := config.DB.QueryRow(query, userID, req.Tipo, req.Symbol, req.Condicao, req.Valor, req.WebhookURL, secret).Scan(&id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao criar alerta"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"mensagem": "Alerta criado com sucesso", "id": id, "segredo": secret})
+}
+
+// GetAlerts godoc
+// @Summary Listar alertas
+// @Description Lista os alertas do usuário com situação e status da entrega
+// @Tags alerts
+// @Accept  json
+// @Produce  json
+// @Param X-User-ID header string true "Identificador do usuário"
+// @Success 200 {array} models.Alerta
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /alerts [get]
+func GetAlerts(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	alertas := []models.Alerta{}
+	if err := config.DB.Select(&alertas, "SELECT * FROM alertas WHERE user_id = $1 ORDER BY id", userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar alertas"})
+		return
+	}
+
+	c.JSON(http.StatusOK, alertas)
+}
+
+// DeleteAlert godoc
+// @Summary Remover alerta
+// @Description Remove um alerta do usuário
+// @Tags alerts
+// @Accept  json
+// @Produce  json
+// @Param X-User-ID header string true "Identificador do usuário"
+// @Param id path int true "ID do alerta"
+// @Success 200 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /alerts/{id} [delete]
+func DeleteAlert(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	result, err := config.DB.Exec("DELETE FROM alertas WHERE id = $1 AND user_id = $2", c.Param("id"), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao remover alerta"})
+		return
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"erro": "Alerta não encontrado"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"mensagem": "Alerta removido com sucesso"})
+}
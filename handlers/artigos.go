@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"net/http"
+
+	"go-br-finance-api/config"
+	"go-br-finance-api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func validateArtigo(c *gin.Context, artigo models.Artigo) bool {
+	if len(artigo.Titulo) < 3 || len(artigo.Titulo) > 200 {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Título deve ter entre 3 e 200 caracteres"})
+		return false
+	}
+
+	if len(artigo.Conteudo) < 10 || len(artigo.Conteudo) > 20000 {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Conteúdo deve ter entre 10 e 20000 caracteres"})
+		return false
+	}
+	return true
+}
+
+// GetArtigos godoc
+// @Summary Listar artigos
+// @Description Obtém os artigos educacionais usados como base de conhecimento do chat
+// @Tags articles
+// @Accept  json
+// @Produce  json
+// @Success 200 {array} models.Artigo
+// @Failure 500 {object} map[string]string
+// @Router /artigos [get]
+func GetArtigos(c *gin.Context) {
+	artigos := []models.Artigo{}
+	err := config.DB.Select(&artigos, "SELECT * FROM artigos ORDER BY id")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar artigos"})
+		return
+	}
+
+	c.JSON(http.StatusOK, artigos)
+}
+
+// CreateArtigo godoc
+// @Summary Criar artigo
+// @Description Cria um artigo educacional e o indexa para o chat (requer X-Admin-Token)
+// @Tags articles
+// @Accept  json
+// @Produce  json
+// @Param X-Admin-Token header string true "Token de administrador"
+// @Param request body models.Artigo true "Dados do artigo"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /artigos [post]
+func CreateArtigo(c *gin.Context) {
+	var artigo models.Artigo
+	if err := c.ShouldBindJSON(&artigo); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Dados inválidos", "detalhes": err.Error()})
+		return
+	}
+
+	if !validateArtigo(c, artigo) {
+		return
+	}
+
+	query := "INSERT INTO artigos (titulo, conteudo, url) VALUES ($1, $2, $3) RETURNING id"
+	var id int
+	err := config.DB.QueryRow(query, artigo.Titulo, artigo.Conteudo, artigo.URL).Scan(&id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao criar artigo"})
+		return
+	}
+
+	requestReindex()
+	c.JSON(http.StatusCreated, gin.H{"mensagem": "Artigo criado com sucesso", "id": id})
+}
+
+// UpdateArtigo godoc
+// @Summary Atualizar artigo
+// @Description Atualiza um artigo educacional e reindexa seu conteúdo (requer X-Admin-Token)
+// @Tags articles
+// @Accept  json
+// @Produce  json
+// @Param X-Admin-Token header string true "Token de administrador"
+// @Param id path int true "ID do artigo"
+// @Param request body models.Artigo true "Dados atualizados do artigo"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /artigos/{id} [put]
+func UpdateArtigo(c *gin.Context) {
+	id := c.Param("id")
+	var artigo models.Artigo
+	if err := c.ShouldBindJSON(&artigo); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Dados inválidos", "detalhes": err.Error()})
+		return
+	}
+
+	if !validateArtigo(c, artigo) {
+		return
+	}
+
+	query := "UPDATE artigos SET titulo = $1, conteudo = $2, url = $3, updated_at = CURRENT_TIMESTAMP WHERE id = $4"
+	result, err := config.DB.Exec(query, artigo.Titulo, artigo.Conteudo, artigo.URL, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao atualizar artigo"})
+		return
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"erro": "Artigo não encontrado"})
+		return
+	}
+
+	requestReindex()
+	c.JSON(http.StatusOK, gin.H{"mensagem": "Artigo atualizado com sucesso"})
+}
+
+// DeleteArtigo godoc
+// @Summary Deletar artigo
+// @Description Remove um artigo educacional e seus trechos indexados (requer X-Admin-Token)
+// @Tags articles
+// @Accept  json
+// @Produce  json
+// @Param X-Admin-Token header string true "Token de administrador"
+// @Param id path int true "ID do artigo"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /artigos/{id} [delete]
+func DeleteArtigo(c *gin.Context) {
+	id := c.Param("id")
+
+	query := "DELETE FROM artigos WHERE id = $1"
+	result, err := config.DB.Exec(query, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao deletar artigo"})
+		return
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"erro": "Artigo não encontrado"})
+		return
+	}
+
+	requestReindex()
+	c.JSON(http.StatusOK, gin.H{"mensagem": "Artigo deletado com sucesso"})
+}
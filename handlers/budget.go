@@ -0,0 +1,443 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"go-br-finance-api/config"
+	"go-br-finance-api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	monthLayout = "2006-01"
+
+	categoriaColumns   = `id, user_id, nome, tipo, created_at`
+	lancamentoColumns  = `id, user_id, categoria_id, descricao, tipo, valor, to_char(data, 'YYYY-MM-DD') AS data, recorrencia_id, conta, fitid, created_at`
+	recorrenciaColumns = `id, user_id, categoria_id, descricao, tipo, valor, dia, to_char(inicio, 'YYYY-MM-DD') AS inicio,
+		to_char(fim, 'YYYY-MM-DD') AS fim, to_char(gerado_ate, 'YYYY-MM-DD') AS gerado_ate, created_at`
+)
+
+type BudgetCategoria struct {
+	CategoriaID *int    `json:"categoria_id"` // nulo para lançamentos sem categoria
+	Nome        string  `json:"nome"`
+	Tipo        string  `json:"tipo"`
+	Planejado   float64 `json:"planejado"`
+	Realizado   float64 `json:"realizado"`
+	Restante    float64 `json:"restante"`
+	Percentual  float64 `json:"percentual"` // realizado sobre planejado
+}
+
+type BudgetSummary struct {
+	Mes        string            `json:"mes"`
+	Receitas   float64           `json:"receitas"`
+	Despesas   float64           `json:"despesas"`
+	Saldo      float64           `json:"saldo"`
+	Planejado  float64           `json:"planejado"` // total planejado para despesas
+	Categorias []BudgetCategoria `json:"categorias"`
+}
+
+// parseMes interpreta o parâmetro month (YYYY-MM), usando o mês atual quando vazio
+func parseMes(s string) (time.Time, error) {
+	if s == "" {
+		now := time.Now().In(saoPauloLocation())
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC), nil
+	}
+	mes, err := time.Parse(monthLayout, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("month deve estar no formato YYYY-MM")
+	}
+	return mes, nil
+}
+
+func validTipoLancamento(tipo string) bool {
+	return tipo == models.LancamentoDespesa || tipo == models.LancamentoReceita
+}
+
+// categoriaDoUsuario confirma que a categoria pertence ao usuário e retorna o seu tipo
+func categoriaDoUsuario(userID string, categoriaID int) (string, error) {
+	var tipo string
+	err := config.DB.Get(&tipo, "SELECT tipo FROM orcamento_categorias WHERE id = $1 AND user_id = $2", categoriaID, userID)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("categoria %d não encontrada", categoriaID)
+	}
+	return tipo, err
+}
+
+// ocorrenciaNoMes retorna a data da recorrência no mês, limitando o dia ao último dia do mês
+func ocorrenciaNoMes(ano int, mes time.Month, dia int) time.Time {
+	ultimoDia := time.Date(ano, mes+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	if dia > ultimoDia {
+		dia = ultimoDia
+	}
+	return time.Date(ano, mes, dia, 0, 0, 0, 0, time.UTC)
+}
+
+// materializeRecorrencias gera os lançamentos recorrentes do usuário até a data informada.
+// Cada recorrência guarda até quando já foi gerada, então lançamentos removidos não voltam.
+// As recorrências ficam bloqueadas (FOR UPDATE) durante a geração: uma requisição concorrente
+// espera e, ao ler o gerado_ate já atualizado, não gera os mesmos lançamentos de novo.
+func materializeRecorrencias(userID string, ate time.Time) error {
+	tx, err := config.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var recorrencias []models.Recorrencia
+	query := "SELECT " + recorrenciaColumns + ` FROM orcamento_recorrencias
+		WHERE user_id = $1 AND inicio <= $2 AND (gerado_ate IS NULL OR gerado_ate < $2) AND (fim IS NULL OR gerado_ate IS NULL OR gerado_ate < fim)
+		FOR UPDATE`
+	if err := tx.Select(&recorrencias, query, userID, ate.Format(dateLayout)); err != nil {
+		return err
+	}
+	if len(recorrencias) == 0 {
+		return nil
+	}
+
+	for _, r := range recorrencias {
+		inicio, _ := time.Parse(dateLayout, r.Inicio)
+		de := inicio
+		if r.GeradoAte != nil {
+			geradoAte, _ := time.Parse(dateLayout, *r.GeradoAte)
+			de = geradoAte.AddDate(0, 0, 1)
+		}
+		limite := ate
+		if r.Fim != nil {
+			if fim, err := time.Parse(dateLayout, *r.Fim); err == nil && fim.Before(limite) {
+				limite = fim
+			}
+		}
+
+		for mes := time.Date(de.Year(), de.Month(), 1, 0, 0, 0, 0, time.UTC); !mes.After(limite); mes = mes.AddDate(0, 1, 0) {
+			data := ocorrenciaNoMes(mes.Year(), mes.Month(), r.Dia)
+			if data.Before(de) || data.After(limite) {
+				continue
+			}
+			_, err := tx.Exec(`INSERT INTO orcamento_lancamentos (user_id, categoria_id, descricao, tipo, valor, data, recorrencia_id)
+				VALUES ($1, $2, $3, $4, $5, $6, $7)`, userID, r.CategoriaID, r.Descricao, r.Tipo, r.Valor, data.Format(dateLayout), r.ID)
+			if err != nil {
+				return err
+			}
+		}
+
+		if _, err := tx.Exec("UPDATE orcamento_recorrencias SET gerado_ate = $1 WHERE id = $2", limite.Format(dateLayout), r.ID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// today retorna a data atual em São Paulo, sem horário
+func today() time.Time {
+	now := time.Now().In(saoPauloLocation())
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// GetBudgetCategories godoc
+// @Summary Listar categorias do orçamento
+// @Description Lista as categorias de despesa e receita do usuário
+// @Tags budget
+// @Accept  json
+// @Produce  json
+// @Param X-User-ID header string true "Identificador do usuário"
+// @Success 200 {array} models.Categoria
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /budget/categories [get]
+func GetBudgetCategories(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	categorias := []models.Categoria{}
+	query := "SELECT " + categoriaColumns + " FROM orcamento_categorias WHERE user_id = $1 ORDER BY tipo, nome"
+	if err := config.DB.Select(&categorias, query, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar categorias"})
+		return
+	}
+
+	c.JSON(http.StatusOK, categorias)
+}
+
+// CreateBudgetCategory godoc
+// @Summary Criar categoria do orçamento
+// @Description Cria uma categoria de despesa (padrão) ou receita
+// @Tags budget
+// @Accept  json
+// @Produce  json
+// @Param X-User-ID header string true "Identificador do usuário"
+// @Param request body models.Categoria true "Dados da categoria"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /budget/categories [post]
+func CreateBudgetCategory(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var categoria models.Categoria
+	if err := c.ShouldBindJSON(&categoria); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Dados inválidos", "detalhes": err.Error()})
+		return
+	}
+
+	categoria.Nome = strings.TrimSpace(categoria.Nome)
+	categoria.Tipo = strings.ToLower(strings.TrimSpace(categoria.Tipo))
+	if categoria.Tipo == "" {
+		categoria.Tipo = models.LancamentoDespesa
+	}
+	if len(categoria.Nome) < 2 || len(categoria.Nome) > 60 {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "nome deve ter entre 2 e 60 caracteres"})
+		return
+	}
+	if !validTipoLancamento(categoria.Tipo) {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "tipo deve ser despesa ou receita"})
+		return
+	}
+
+	query := `INSERT INTO orcamento_categorias (user_id, nome, tipo) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, nome) DO NOTHING RETURNING id`
+	var id int
+	err := config.DB.QueryRow(query, userID, categoria.Nome, categoria.Tipo).Scan(&id)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusConflict, gin.H{"erro": "Já existe uma categoria com esse nome"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao criar categoria"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"mensagem": "Categoria criada com sucesso", "id": id})
+}
+
+// DeleteBudgetCategory godoc
+// @Summary Remover categoria do orçamento
+// @Description Remove a categoria e seus limites; os lançamentos da categoria ficam sem categoria
+// @Tags budget
+// @Accept  json
+// @Produce  json
+// @Param X-User-ID header string true "Identificador do usuário"
+// @Param id path int true "ID da categoria"
+// @Success 200 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /budget/categories/{id} [delete]
+func DeleteBudgetCategory(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	result, err := config.DB.Exec("DELETE FROM orcamento_categorias WHERE id = $1 AND user_id = $2", c.Param("id"), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao remover categoria"})
+		return
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"erro": "Categoria não encontrada"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"mensagem": "Categoria removida com sucesso"})
+}
+
+// GetBudgetLimits godoc
+// @Summary Listar orçamento do mês
+// @Description Lista os valores planejados por categoria no mês
+// @Tags budget
+// @Accept  json
+// @Produce  json
+// @Param X-User-ID header string true "Identificador do usuário"
+// @Param month query string false "Mês no formato YYYY-MM (padrão: mês atual)"
+// @Success 200 {array} models.LimiteOrcamento
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /budget/limits [get]
+func GetBudgetLimits(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	mes, err := parseMes(c.Query("month"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": err.Error()})
+		return
+	}
+
+	limites := []models.LimiteOrcamento{}
+	query := `SELECT l.categoria_id, l.mes, l.valor FROM orcamento_limites l
+		JOIN orcamento_categorias c ON c.id = l.categoria_id
+		WHERE c.user_id = $1 AND l.mes = $2 ORDER BY l.categoria_id`
+	if err := config.DB.Select(&limites, query, userID, mes.Format(monthLayout)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar orçamento"})
+		return
+	}
+
+	c.JSON(http.StatusOK, limites)
+}
+
+// SetBudgetLimit godoc
+// @Summary Definir orçamento de uma categoria
+// @Description Define o valor planejado de uma categoria em um mês; valor 0 remove o planejamento
+// @Tags budget
+// @Accept  json
+// @Produce  json
+// @Param X-User-ID header string true "Identificador do usuário"
+// @Param request body models.LimiteOrcamento true "Categoria, mês (YYYY-MM) e valor"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /budget/limits [put]
+func SetBudgetLimit(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var limite models.LimiteOrcamento
+	if err := c.ShouldBindJSON(&limite); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Dados inválidos", "detalhes": err.Error()})
+		return
+	}
+
+	if _, err := time.Parse(monthLayout, limite.Mes); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "mes deve estar no formato YYYY-MM"})
+		return
+	}
+	if limite.Valor < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "valor não pode ser negativo"})
+		return
+	}
+	if _, err := categoriaDoUsuario(userID, limite.CategoriaID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": err.Error()})
+		return
+	}
+
+	var err error
+	if limite.Valor == 0 {
+		_, err = config.DB.Exec("DELETE FROM orcamento_limites WHERE categoria_id = $1 AND mes = $2", limite.CategoriaID, limite.Mes)
+	} else {
+		_, err = config.DB.Exec(`INSERT INTO orcamento_limites (categoria_id, mes, valor) VALUES ($1, $2, $3)
+			ON CONFLICT (categoria_id, mes) DO UPDATE SET valor = EXCLUDED.valor`, limite.CategoriaID, limite.Mes, limite.Valor)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao salvar orçamento"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"mensagem": "Orçamento salvo com sucesso"})
+}
+
+// GetBudgetSummary godoc
+// @Summary Resumo do orçamento
+// @Description Compara o realizado com o planejado por categoria no mês, incluindo os lançamentos recorrentes já vencidos
+// @Tags budget
+// @Accept  json
+// @Produce  json
+// @Param X-User-ID header string true "Identificador do usuário"
+// @Param month query string false "Mês no formato YYYY-MM (padrão: mês atual)"
+// @Success 200 {object} BudgetSummary
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /budget/summary [get]
+func GetBudgetSummary(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	mes, err := parseMes(c.Query("month"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": err.Error()})
+		return
+	}
+
+	if err := materializeRecorrencias(userID, today()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao gerar lançamentos recorrentes"})
+		return
+	}
+
+	// Planejado e realizado por categoria, mais uma linha para lançamentos sem categoria
+	var rows []struct {
+		CategoriaID *int            `db:"categoria_id"`
+		Nome        sql.NullString  `db:"nome"`
+		Tipo        string          `db:"tipo"`
+		Planejado   sql.NullFloat64 `db:"planejado"`
+		Realizado   float64         `db:"realizado"`
+	}
+	query := `WITH realizado AS (
+			SELECT categoria_id, tipo, SUM(valor) AS total FROM orcamento_lancamentos
+			WHERE user_id = $1 AND data >= $2 AND data < $3
+			GROUP BY categoria_id, tipo
+		)
+		SELECT c.id AS categoria_id, c.nome, c.tipo, l.valor AS planejado, COALESCE(r.total, 0) AS realizado
+		FROM orcamento_categorias c
+		LEFT JOIN orcamento_limites l ON l.categoria_id = c.id AND l.mes = $4
+		LEFT JOIN realizado r ON r.categoria_id = c.id AND r.tipo = c.tipo
+		WHERE c.user_id = $1
+		UNION ALL
+		SELECT NULL, NULL, r.tipo, NULL, r.total FROM realizado r WHERE r.categoria_id IS NULL`
+	fim := mes.AddDate(0, 1, 0)
+	err = config.DB.Select(&rows, query, userID, mes.Format(dateLayout), fim.Format(dateLayout), mes.Format(monthLayout))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao calcular resumo do orçamento"})
+		return
+	}
+
+	summary := BudgetSummary{Mes: mes.Format(monthLayout), Categorias: []BudgetCategoria{}}
+	for _, row := range rows {
+		categoria := BudgetCategoria{
+			CategoriaID: row.CategoriaID,
+			Nome:        row.Nome.String,
+			Tipo:        row.Tipo,
+			Planejado:   row.Planejado.Float64,
+			Realizado:   row.Realizado,
+		}
+		if !row.Nome.Valid {
+			categoria.Nome = "Sem categoria"
+		}
+		categoria.Restante = categoria.Planejado - categoria.Realizado
+		if categoria.Planejado > 0 {
+			categoria.Percentual = categoria.Realizado / categoria.Planejado * 100
+		}
+
+		if categoria.Tipo == models.LancamentoReceita {
+			summary.Receitas += categoria.Realizado
+		} else {
+			summary.Despesas += categoria.Realizado
+			summary.Planejado += categoria.Planejado
+		}
+		if categoria.CategoriaID != nil || categoria.Realizado > 0 {
+			summary.Categorias = append(summary.Categorias, categoria)
+		}
+	}
+	summary.Saldo = summary.Receitas - summary.Despesas
+
+	sort.SliceStable(summary.Categorias, func(i, j int) bool {
+		if summary.Categorias[i].Tipo != summary.Categorias[j].Tipo {
+			return summary.Categorias[i].Tipo == models.LancamentoDespesa
+		}
+		return summary.Categorias[i].Realizado > summary.Categorias[j].Realizado
+	})
+
+	c.JSON(http.StatusOK, summary)
+}
@@ -0,0 +1,345 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"go-br-finance-api/config"
+	"go-br-finance-api/importer"
+	"go-br-finance-api/models"
+	"go-br-finance-api/search"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+type ImportedLancamento struct {
+	models.Lancamento
+	Categoria string `json:"categoria,omitempty"`
+	Duplicado bool   `json:"duplicado"`
+}
+
+type BudgetImportResponse struct {
+	Formato       string               `json:"formato"` // ofx ou csv
+	DryRun        bool                 `json:"dry_run"`
+	Lancamentos   []ImportedLancamento `json:"lancamentos"`
+	Novos         int                  `json:"novos"`
+	Duplicados    int                  `json:"duplicados"`
+	Categorizados int                  `json:"categorizados"`
+	Importados    int                  `json:"importados"`
+}
+
+type regraCompilada struct {
+	padrao      string
+	categoriaID int
+	nome        string
+	tipo        string
+}
+
+// loadRegras retorna as regras do usuário, das mais específicas (padrão mais longo) para as mais genéricas
+func loadRegras(userID string) ([]regraCompilada, error) {
+	var rows []struct {
+		Padrao      string `db:"padrao"`
+		CategoriaID int    `db:"categoria_id"`
+		Nome        string `db:"nome"`
+		Tipo        string `db:"tipo"`
+	}
+	query := `SELECT r.padrao, r.categoria_id, c.nome, c.tipo FROM orcamento_regras r
+		JOIN orcamento_categorias c ON c.id = r.categoria_id
+		WHERE r.user_id = $1 ORDER BY length(r.padrao) DESC, r.id`
+	if err := config.DB.Select(&rows, query, userID); err != nil {
+		return nil, err
+	}
+
+	regras := make([]regraCompilada, 0, len(rows))
+	for _, r := range rows {
+		regras = append(regras, regraCompilada{padrao: search.Fold(r.Padrao), categoriaID: r.CategoriaID, nome: r.Nome, tipo: r.Tipo})
+	}
+	return regras, nil
+}
+
+// categorizar aplica a primeira regra cujo padrão aparece na descrição e cuja categoria tem o mesmo tipo
+func categorizar(regras []regraCompilada, descricao, tipo string) *regraCompilada {
+	texto := search.Fold(descricao)
+	for i, r := range regras {
+		if r.tipo == tipo && strings.Contains(texto, r.padrao) {
+			return &regras[i]
+		}
+	}
+	return nil
+}
+
+// ImportBudgetStatement godoc
+// @Summary Importar extrato bancário
+// @Description Importa um extrato OFX ou CSV (Nubank, Inter, Itaú, Bradesco e similares) como lançamentos do orçamento, categorizando pelas regras do usuário e ignorando transações já importadas (FITID de cada conta). Use dry_run=true para pré-visualizar
+// @Tags budget
+// @Accept  multipart/form-data
+// @Produce  json
+// @Param X-User-ID header string true "Identificador do usuário"
+// @Param file formData file true "Extrato em OFX ou CSV"
+// @Param dry_run query bool false "Apenas pré-visualizar, sem gravar" default(false)
+// @Success 200 {object} BudgetImportResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /budget/import [post]
+func ImportBudgetStatement(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	dryRun := c.DefaultQuery("dry_run", "false") == "true"
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Arquivo obrigatório no campo 'file'"})
+		return
+	}
+	if fileHeader.Size > maxImportSize {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Arquivo maior que 10 MB"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Não foi possível abrir o arquivo"})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxImportSize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Não foi possível ler o arquivo"})
+		return
+	}
+
+	response := BudgetImportResponse{Formato: "csv", DryRun: dryRun, Lancamentos: []ImportedLancamento{}}
+	var extrato []importer.ExtratoLancamento
+	if importer.IsOFX(data) {
+		response.Formato = "ofx"
+		extrato, err = importer.ParseOFX(data)
+	} else {
+		var rows [][]string
+		rows, err = importer.ReadSpreadsheet(data)
+		if err == nil {
+			extrato, err = importer.ParseBankCSV(rows)
+		}
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": err.Error()})
+		return
+	}
+
+	regras, err := loadRegras(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar regras de categorização"})
+		return
+	}
+
+	// Sem FITID (CSV sem identificador) o lançamento é identificado pelo hash dos seus campos
+	// O FITID só é único dentro da conta, então a duplicidade é verificada pelo par (conta, fitid)
+	var fitids []string
+	var keys []string
+	occurrences := make(map[string]int)
+	for _, e := range extrato {
+		if e.Valor == 0 {
+			continue
+		}
+		fitid := e.ID
+		if fitid == "" {
+			key := fmt.Sprint(e.Data, e.Descricao, e.Valor)
+			occurrences[key]++
+			fitid = "hash:" + importHash(userID, occurrences[key], "extrato", e.Data, e.Descricao, e.Valor)
+		}
+
+		lancamento := ImportedLancamento{Lancamento: models.Lancamento{
+			Descricao: strings.TrimSpace(e.Descricao),
+			Tipo:      models.LancamentoReceita,
+			Valor:     e.Valor,
+			Data:      e.Data,
+			Conta:     e.Conta,
+			FITID:     &fitid,
+		}}
+		if lancamento.Descricao == "" {
+			lancamento.Descricao = "Lançamento importado"
+		}
+		if e.Valor < 0 {
+			lancamento.Tipo = models.LancamentoDespesa
+			lancamento.Valor = -e.Valor
+		}
+		if regra := categorizar(regras, lancamento.Descricao, lancamento.Tipo); regra != nil {
+			lancamento.CategoriaID = &regra.categoriaID
+			lancamento.Categoria = regra.nome
+			response.Categorizados++
+		}
+
+		response.Lancamentos = append(response.Lancamentos, lancamento)
+		fitids = append(fitids, fitid)
+		keys = append(keys, e.Conta+"\x00"+fitid)
+	}
+
+	existing := make(map[string]bool)
+	if len(fitids) > 0 {
+		var found []struct {
+			Conta string `db:"conta"`
+			FITID string `db:"fitid"`
+		}
+		err := config.DB.Select(&found, "SELECT conta, fitid FROM orcamento_lancamentos WHERE user_id = $1 AND fitid = ANY($2)", userID, pq.Array(fitids))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao verificar duplicidades"})
+			return
+		}
+		for _, f := range found {
+			existing[f.Conta+"\x00"+f.FITID] = true
+		}
+	}
+
+	for i := range response.Lancamentos {
+		if existing[keys[i]] {
+			response.Lancamentos[i].Duplicado = true
+			response.Duplicados++
+		}
+	}
+	response.Novos = len(response.Lancamentos) - response.Duplicados
+
+	if dryRun {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	tx, err := config.DB.Beginx()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao importar lançamentos"})
+		return
+	}
+	defer tx.Rollback()
+
+	for _, l := range response.Lancamentos {
+		if l.Duplicado {
+			continue
+		}
+		result, err := tx.Exec(`INSERT INTO orcamento_lancamentos (user_id, categoria_id, descricao, tipo, valor, data, conta, fitid)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8) ON CONFLICT (user_id, conta, fitid) DO NOTHING`,
+			userID, l.CategoriaID, l.Descricao, l.Tipo, l.Valor, l.Data, l.Conta, l.FITID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao importar lançamentos"})
+			return
+		}
+		rowsAffected, _ := result.RowsAffected()
+		response.Importados += int(rowsAffected)
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao importar lançamentos"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetBudgetRules godoc
+// @Summary Listar regras de categorização
+// @Description Lista as regras usadas para categorizar lançamentos importados
+// @Tags budget
+// @Accept  json
+// @Produce  json
+// @Param X-User-ID header string true "Identificador do usuário"
+// @Success 200 {array} models.RegraCategoria
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /budget/rules [get]
+func GetBudgetRules(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	regras := []models.RegraCategoria{}
+	if err := config.DB.Select(&regras, "SELECT * FROM orcamento_regras WHERE user_id = $1 ORDER BY id", userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar regras"})
+		return
+	}
+
+	c.JSON(http.StatusOK, regras)
+}
+
+// CreateBudgetRule godoc
+// @Summary Criar regra de categorização
+// @Description Lançamentos importados cuja descrição contém o padrão (sem diferenciar maiúsculas e acentos) recebem a categoria. Havendo mais de uma regra, vale o padrão mais longo
+// @Tags budget
+// @Accept  json
+// @Produce  json
+// @Param X-User-ID header string true "Identificador do usuário"
+// @Param request body models.RegraCategoria true "Padrão e categoria"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /budget/rules [post]
+func CreateBudgetRule(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var regra models.RegraCategoria
+	if err := c.ShouldBindJSON(&regra); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Dados inválidos", "detalhes": err.Error()})
+		return
+	}
+
+	regra.Padrao = strings.TrimSpace(regra.Padrao)
+	if search.Fold(regra.Padrao) == "" || len(regra.Padrao) > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "padrao deve ter entre 1 e 100 caracteres"})
+		return
+	}
+	if _, err := categoriaDoUsuario(userID, regra.CategoriaID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": err.Error()})
+		return
+	}
+
+	query := "INSERT INTO orcamento_regras (user_id, padrao, categoria_id) VALUES ($1, $2, $3) RETURNING id"
+	var id int
+	if err := config.DB.QueryRow(query, userID, regra.Padrao, regra.CategoriaID).Scan(&id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao criar regra"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"mensagem": "Regra criada com sucesso", "id": id})
+}
+
+// DeleteBudgetRule godoc
+// @Summary Remover regra de categorização
+// @Description Remove uma regra do usuário; lançamentos já categorizados não mudam
+// @Tags budget
+// @Accept  json
+// @Produce  json
+// @Param X-User-ID header string true "Identificador do usuário"
+// @Param id path int true "ID da regra"
+// @Success 200 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /budget/rules/{id} [delete]
+func DeleteBudgetRule(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	result, err := config.DB.Exec("DELETE FROM orcamento_regras WHERE id = $1 AND user_id = $2", c.Param("id"), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao remover regra"})
+		return
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"erro": "Regra não encontrada"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"mensagem": "Regra removida com sucesso"})
+}
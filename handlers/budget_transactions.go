@@ -0,0 +1,299 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"go-br-finance-api/config"
+	"go-br-finance-api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// resolveTipoLancamento valida a categoria e o tipo; sem tipo informado vale o da categoria
+func resolveTipoLancamento(userID string, categoriaID *int, tipo string) (string, error) {
+	tipo = strings.ToLower(strings.TrimSpace(tipo))
+	if categoriaID != nil {
+		tipoCategoria, err := categoriaDoUsuario(userID, *categoriaID)
+		if err != nil {
+			return "", err
+		}
+		if tipo == "" {
+			tipo = tipoCategoria
+		}
+		if tipo != tipoCategoria {
+			return "", fmt.Errorf("tipo do lançamento difere do tipo da categoria (%s)", tipoCategoria)
+		}
+	}
+	if tipo == "" {
+		tipo = models.LancamentoDespesa
+	}
+	if !validTipoLancamento(tipo) {
+		return "", fmt.Errorf("tipo deve ser despesa ou receita")
+	}
+	return tipo, nil
+}
+
+// GetBudgetTransactions godoc
+// @Summary Listar lançamentos do orçamento
+// @Description Lista as despesas e receitas do usuário no mês, incluindo os lançamentos recorrentes já vencidos
+// @Tags budget
+// @Accept  json
+// @Produce  json
+// @Param X-User-ID header string true "Identificador do usuário"
+// @Param month query string false "Mês no formato YYYY-MM (padrão: mês atual)"
+// @Success 200 {array} models.Lancamento
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /budget/transactions [get]
+func GetBudgetTransactions(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	mes, err := parseMes(c.Query("month"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": err.Error()})
+		return
+	}
+
+	if err := materializeRecorrencias(userID, today()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao gerar lançamentos recorrentes"})
+		return
+	}
+
+	lancamentos := []models.Lancamento{}
+	query := "SELECT " + lancamentoColumns + " FROM orcamento_lancamentos WHERE user_id = $1 AND data >= $2 AND data < $3 ORDER BY data, id"
+	err = config.DB.Select(&lancamentos, query, userID, mes.Format(dateLayout), mes.AddDate(0, 1, 0).Format(dateLayout))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar lançamentos"})
+		return
+	}
+
+	c.JSON(http.StatusOK, lancamentos)
+}
+
+// CreateBudgetTransaction godoc
+// @Summary Registrar lançamento
+// @Description Registra uma despesa ou receita. Sem tipo informado, vale o tipo da categoria
+// @Tags budget
+// @Accept  json
+// @Produce  json
+// @Param X-User-ID header string true "Identificador do usuário"
+// @Param request body models.Lancamento true "Dados do lançamento"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /budget/transactions [post]
+func CreateBudgetTransaction(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var lancamento models.Lancamento
+	if err := c.ShouldBindJSON(&lancamento); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Dados inválidos", "detalhes": err.Error()})
+		return
+	}
+
+	lancamento.Descricao = strings.TrimSpace(lancamento.Descricao)
+	if lancamento.Descricao == "" || len(lancamento.Descricao) > 200 {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "descricao deve ter entre 1 e 200 caracteres"})
+		return
+	}
+	if lancamento.Valor <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "valor deve ser positivo"})
+		return
+	}
+	if _, err := time.Parse(dateLayout, lancamento.Data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "data deve estar no formato YYYY-MM-DD"})
+		return
+	}
+	tipo, err := resolveTipoLancamento(userID, lancamento.CategoriaID, lancamento.Tipo)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": err.Error()})
+		return
+	}
+
+	query := `INSERT INTO orcamento_lancamentos (user_id, categoria_id, descricao, tipo, valor, data)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+	var id int
+	err = config.DB.QueryRow(query, userID, lancamento.CategoriaID, lancamento.Descricao, tipo, lancamento.Valor, lancamento.Data).Scan(&id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao registrar lançamento"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"mensagem": "Lançamento registrado com sucesso", "id": id})
+}
+
+// DeleteBudgetTransaction godoc
+// @Summary Remover lançamento
+// @Description Remove um lançamento do usuário. Lançamentos recorrentes removidos não são gerados novamente
+// @Tags budget
+// @Accept  json
+// @Produce  json
+// @Param X-User-ID header string true "Identificador do usuário"
+// @Param id path int true "ID do lançamento"
+// @Success 200 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /budget/transactions/{id} [delete]
+func DeleteBudgetTransaction(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	result, err := config.DB.Exec("DELETE FROM orcamento_lancamentos WHERE id = $1 AND user_id = $2", c.Param("id"), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao remover lançamento"})
+		return
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"erro": "Lançamento não encontrado"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"mensagem": "Lançamento removido com sucesso"})
+}
+
+// GetBudgetRecurring godoc
+// @Summary Listar recorrências
+// @Description Lista as despesas e receitas recorrentes do usuário
+// @Tags budget
+// @Accept  json
+// @Produce  json
+// @Param X-User-ID header string true "Identificador do usuário"
+// @Success 200 {array} models.Recorrencia
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /budget/recurring [get]
+func GetBudgetRecurring(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	recorrencias := []models.Recorrencia{}
+	query := "SELECT " + recorrenciaColumns + " FROM orcamento_recorrencias WHERE user_id = $1 ORDER BY id"
+	if err := config.DB.Select(&recorrencias, query, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar recorrências"})
+		return
+	}
+
+	c.JSON(http.StatusOK, recorrencias)
+}
+
+// CreateBudgetRecurring godoc
+// @Summary Criar recorrência
+// @Description Cadastra uma despesa ou receita mensal (ex: aluguel no dia 5). Os lançamentos são gerados à medida que vencem
+// @Tags budget
+// @Accept  json
+// @Produce  json
+// @Param X-User-ID header string true "Identificador do usuário"
+// @Param request body models.Recorrencia true "Dados da recorrência"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /budget/recurring [post]
+func CreateBudgetRecurring(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var recorrencia models.Recorrencia
+	if err := c.ShouldBindJSON(&recorrencia); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Dados inválidos", "detalhes": err.Error()})
+		return
+	}
+
+	recorrencia.Descricao = strings.TrimSpace(recorrencia.Descricao)
+	if recorrencia.Descricao == "" || len(recorrencia.Descricao) > 200 {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "descricao deve ter entre 1 e 200 caracteres"})
+		return
+	}
+	if recorrencia.Valor <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "valor deve ser positivo"})
+		return
+	}
+	if recorrencia.Dia < 1 || recorrencia.Dia > 31 {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "dia deve estar entre 1 e 31"})
+		return
+	}
+	if recorrencia.Inicio == "" {
+		recorrencia.Inicio = today().Format(dateLayout)
+	}
+	inicio, err := time.Parse(dateLayout, recorrencia.Inicio)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "inicio deve estar no formato YYYY-MM-DD"})
+		return
+	}
+	if recorrencia.Fim != nil {
+		fim, err := time.Parse(dateLayout, *recorrencia.Fim)
+		if err != nil || fim.Before(inicio) {
+			c.JSON(http.StatusBadRequest, gin.H{"erro": "fim deve estar no formato YYYY-MM-DD e não pode ser anterior ao inicio"})
+			return
+		}
+	}
+	tipo, err := resolveTipoLancamento(userID, recorrencia.CategoriaID, recorrencia.Tipo)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": err.Error()})
+		return
+	}
+
+	query := `INSERT INTO orcamento_recorrencias (user_id, categoria_id, descricao, tipo, valor, dia, inicio, fim)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`
+	var id int
+	err = config.DB.QueryRow(query, userID, recorrencia.CategoriaID, recorrencia.Descricao, tipo,
+		recorrencia.Valor, recorrencia.Dia, recorrencia.Inicio, recorrencia.Fim).Scan(&id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao criar recorrência"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"mensagem": "Recorrência criada com sucesso", "id": id})
+}
+
+// DeleteBudgetRecurring godoc
+// @Summary Remover recorrência
+// @Description Encerra a recorrência; os lançamentos já gerados são mantidos
+// @Tags budget
+// @Accept  json
+// @Produce  json
+// @Param X-User-ID header string true "Identificador do usuário"
+// @Param id path int true "ID da recorrência"
+// @Success 200 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /budget/recurring/{id} [delete]
+func DeleteBudgetRecurring(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	result, err := config.DB.Exec("DELETE FROM orcamento_recorrencias WHERE id = $1 AND user_id = $2", c.Param("id"), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao remover recorrência"})
+		return
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"erro": "Recorrência não encontrada"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"mensagem": "Recorrência removida com sucesso"})
+}
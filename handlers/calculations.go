@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	"go-br-finance-api/cache"
@@ -200,7 +202,11 @@ func CalculateInvestment(c *gin.Context) {
 		return
 	}
 
-	// Calcular investimento composto
+	c.JSON(http.StatusOK, simulateInvestment(principal, monthlyDeposit, annualRate, years))
+}
+
+// simulateInvestment projeta o investimento com juros compostos e depósitos mensais
+func simulateInvestment(principal, monthlyDeposit, annualRate float64, years int) InvestmentCalculation {
 	monthlyRate := annualRate / 100 / 12
 	totalMonths := years * 12
 
@@ -212,17 +218,57 @@ func CalculateInvestment(c *gin.Context) {
 		totalInvested += monthlyDeposit
 	}
 
-	totalInterest := finalAmount - totalInvested
-
-	calculation := InvestmentCalculation{
+	return InvestmentCalculation{
 		Principal:      principal,
 		MonthlyDeposit: monthlyDeposit,
 		AnnualRate:     annualRate,
 		Years:          years,
 		TotalInvested:  totalInvested,
 		FinalAmount:    finalAmount,
-		TotalInterest:  totalInterest,
+		TotalInterest:  finalAmount - totalInvested,
+	}
+}
+
+// currencyPattern aceita apenas códigos ISO 4217 (ex: USD), que vão na URL da AwesomeAPI e na chave do cache
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+func validCurrency(moeda string) bool {
+	return currencyPattern.MatchString(moeda)
+}
+
+// fetchExchangeRate retorna a cotação de compra da moeda em reais (ex: USD → 5,43)
+func fetchExchangeRate(moeda string) (float64, error) {
+	moeda = strings.ToUpper(moeda)
+	if !validCurrency(moeda) {
+		return 0, fmt.Errorf("moeda inválida: %q", moeda)
+	}
+	cacheKey := "cambio:" + moeda
+	if cachedData, found := cache.GlobalCache.Get(cacheKey); found {
+		return cachedData.(float64), nil
+	}
+
+	resp, err := http.Get(fmt.Sprintf("https://economia.awesomeapi.com.br/json/last/%s-BRL", moeda))
+	if err != nil {
+		return 0, fmt.Errorf("erro ao buscar câmbio: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return 0, fmt.Errorf("cotação de %s não encontrada", moeda)
+	}
+
+	var cotacoes map[string]struct {
+		Bid string `json:"bid"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&cotacoes); err != nil {
+		return 0, fmt.Errorf("erro ao decodificar câmbio: %w", err)
+	}
+
+	rate, err := strconv.ParseFloat(cotacoes[moeda+"BRL"].Bid, 64)
+	if err != nil || rate <= 0 {
+		return 0, fmt.Errorf("cotação de %s não encontrada", moeda)
 	}
 
-	c.JSON(http.StatusOK, calculation)
+	cache.GlobalCache.Set(cacheKey, rate, time.Minute)
+	return rate, nil
 }
@@ -5,11 +5,16 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log"
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
+	"go-br-finance-api/chatstore"
 	"go-br-finance-api/config"
 	"go-br-finance-api/models"
 
@@ -17,8 +22,13 @@ import (
 )
 
 type ChatRequest struct {
-	SessionID string `json:"session_id" binding:"required"`
-	Message   string `json:"message" binding:"required"`
+	SessionID     string   `json:"session_id" binding:"required"`
+	Message       string   `json:"message" binding:"required"`
+	Profile       string   `json:"profile,omitempty"`        // named prompt profile, e.g. "conservador"
+	Model         string   `json:"model,omitempty"`          // one of the allowed models
+	Temperature   *float64 `json:"temperature,omitempty"`    // 0 to 1.5
+	MaxTokens     *int     `json:"max_tokens,omitempty"`     // 1 to 4096
+	ContextWindow *int     `json:"context_window,omitempty"` // 512 to 131072 tokens
 }
 
 type ChatResponse struct {
@@ -26,170 +36,316 @@ type ChatResponse struct {
 }
 
 type OllamaMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role      string           `json:"role"`
+	Content   string           `json:"content"`
+	ToolCalls []OllamaToolCall `json:"tool_calls,omitempty"`
+	ToolName  string           `json:"tool_name,omitempty"`
 }
 
 type OllamaChatRequest struct {
-	Model       string          `json:"model"`
-	Messages    []OllamaMessage `json:"messages"`
-	Stream      bool            `json:"stream"`
-	Temperature float64         `json:"temperature"`
+	Model    string          `json:"model"`
+	Messages []OllamaMessage `json:"messages"`
+	Stream   bool            `json:"stream"`
+	Options  OllamaOptions   `json:"options"`
+	Tools    []OllamaTool    `json:"tools,omitempty"`
 }
 
 type OllamaChatResponse struct {
-	Message OllamaMessage `json:"message"`
+	Message         OllamaMessage `json:"message"`
+	Done            bool          `json:"done"`
+	PromptEvalCount int           `json:"prompt_eval_count,omitempty"`
+	EvalCount       int           `json:"eval_count,omitempty"`
 }
 
-// WebSearch performs a web search using a simple API (mock for demo)
-func WebSearch(query string) string {
-	// Mock web search - in production, use a real API like SerpAPI or Google Custom Search
-	// For demo, return a fixed response
-	return fmt.Sprintf("Web search results for '%s': [Mock] Latest financial news includes market trends, investment tips, etc.", query)
+// newOllamaRequest creates a POST request to the Ollama API, using OLLAMA_URL and OLLAMA_API_KEY
+func newOllamaRequest(path string, payload interface{}) (*http.Request, error) {
+	ollamaURL := os.Getenv("OLLAMA_URL")
+	if ollamaURL == "" {
+		ollamaURL = "http://localhost:11434"
+	}
+
+	reqBody, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequest("POST", ollamaURL+path, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if ollamaAPIKey := os.Getenv("OLLAMA_API_KEY"); ollamaAPIKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+ollamaAPIKey)
+	}
+	return httpReq, nil
+}
+
+// ollamaComplete sends the messages to the deployment model without streaming and returns the full answer
+func ollamaComplete(messages []OllamaMessage) (string, error) {
+	settings := defaultChatSettings()
+	settings.Options.Temperature = 0.3
+	httpReq, err := newOllamaRequest("/api/chat", OllamaChatRequest{
+		Model:    settings.Model,
+		Messages: messages,
+		Stream:   false,
+		Options:  settings.Options,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	client := &http.Client{Timeout: 2 * time.Minute}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("ollama returned status %d", resp.StatusCode)
+	}
+
+	var result OllamaChatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(result.Message.Content), nil
+}
+
+// streamOllamaRound sends one streaming chat request, passing content chunks to onContent. The result
+// holds the full content, any tool calls the model makes and the token counts of the final chunk.
+func streamOllamaRound(settings chatSettings, messages []OllamaMessage, tools []OllamaTool, onContent func(string)) (OllamaChatResponse, error) {
+	httpReq, err := newOllamaRequest("/api/chat", OllamaChatRequest{
+		Model:    settings.Model,
+		Messages: messages,
+		Stream:   true,
+		Options:  settings.Options,
+		Tools:    tools,
+	})
+	if err != nil {
+		return OllamaChatResponse{}, err
+	}
+
+	client := &http.Client{}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return OllamaChatResponse{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return OllamaChatResponse{}, fmt.Errorf("ollama returned status %d", resp.StatusCode)
+	}
+
+	var content strings.Builder
+	result := OllamaChatResponse{Message: OllamaMessage{Role: "assistant"}}
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+
+		var chunk OllamaChatResponse
+		if err := json.Unmarshal([]byte(line), &chunk); err != nil {
+			continue // Skip invalid lines
+		}
+
+		result.Message.ToolCalls = append(result.Message.ToolCalls, chunk.Message.ToolCalls...)
+		if chunk.Message.Content != "" {
+			content.WriteString(chunk.Message.Content)
+			onContent(chunk.Message.Content)
+		}
+		if chunk.Done {
+			result.Done = true
+			result.PromptEvalCount = chunk.PromptEvalCount
+			result.EvalCount = chunk.EvalCount
+		}
+	}
+
+	result.Message.Content = content.String()
+	return result, scanner.Err()
 }
 
 // ChatWithOllama godoc
 // @Summary Chat with financial AI model
-// @Description Send a message to the Ollama financial model and get a streamed response, with conversation saved in Redis
+// @Description Send a message to the Ollama financial model and get a streamed response, with the conversation saved in the chat store
 // @Tags chat
 // @Accept  json
 // @Produce  text/event-stream
+// @Param X-User-ID header string true "User ID (not authenticated; set it from a trusted gateway)"
 // @Param request body ChatRequest true "Chat request"
 // @Success 200 {string} string "Streamed response"
 // @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /chat [post]
 func ChatWithOllama(c *gin.Context) {
+	userID, ok := currentChatUserID(c)
+	if !ok {
+		return
+	}
+
 	var req ChatRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "details": err.Error()})
 		return
 	}
 
+	settings, err := resolveChatSettings(req)
+	if errors.Is(err, errInvalidChatSettings) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load chat profile"})
+		return
+	}
+
 	ctx := context.Background()
-	sessionKey := "chat:" + req.SessionID
-
-	// Retrieve conversation from Redis (if available)
-	var conversation models.Conversation
-	if config.RedisClient != nil {
-		conversationJSON, err := config.RedisClient.Get(ctx, sessionKey).Result()
-		if err == nil {
-			// Parse existing conversation
-			json.Unmarshal([]byte(conversationJSON), &conversation)
-		} else {
-			// New conversation
-			conversation = models.Conversation{
-				SessionID: req.SessionID,
-				Messages:  []models.Message{},
-			}
-		}
-	} else {
-		// No Redis, new conversation each time
+	conversation, found, err := config.ChatStore.Load(ctx, req.SessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load conversation"})
+		return
+	}
+	if found && conversation.UserID == "" {
+		// Session saved before sessions had owners: start a new conversation in its place
+		// instead of exposing the old messages to whoever knows the id
+		found = false
+	}
+	if found && !ownsConversation(conversation, userID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	if !found {
 		conversation = models.Conversation{
 			SessionID: req.SessionID,
+			Title:     chatTitle(req.Message),
+			CreatedAt: now,
 			Messages:  []models.Message{},
 		}
 	}
+	conversation.UserID = userID
+	conversation.UpdatedAt = now
 
 	// Append user message
-	conversation.Messages = append(conversation.Messages, models.Message{
-		Role:    "user",
-		Content: req.Message,
-	})
-
-	// Check if web search is needed
-	if strings.Contains(strings.ToLower(req.Message), "search") || strings.Contains(strings.ToLower(req.Message), "web") {
-		searchResults := WebSearch(req.Message)
-		// Append search results to the last user message
-		conversation.Messages[len(conversation.Messages)-1].Content += "\n\nWeb Search Results:\n" + searchResults
+	userMessage := models.Message{
+		Role:      "user",
+		Content:   req.Message,
+		CreatedAt: now,
+		Tokens:    estimateTokens(req.Message),
 	}
+	conversation.Messages = append(conversation.Messages, userMessage)
 
 	// Prepare messages for Ollama
 	var ollamaMessages []OllamaMessage
 	// Add system message
 	ollamaMessages = append(ollamaMessages, OllamaMessage{
 		Role:    "system",
-		Content: "Você é um consultor financeiro brasileiro. Forneça conselhos em português brasileiro, pesquisando na web as melhores informações para investir. Use fontes confiáveis e mostre seu raciocínio passo a passo. Mantenha as respostas concisas, com no máximo 300 caracteres. Responda sempre em português brasileiro, nunca em inglês.",
+		Content: settings.SystemPrompt + " " + chatToolInstructions,
 	})
-	for _, msg := range conversation.Messages {
+
+	// Add the recommendations and articles relevant to the question
+	passages, err := retrievePassages(req.Message)
+	if err != nil {
+		log.Println("⚠️  Erro ao buscar conteúdo para o chat:", err)
+	}
+	if len(passages) > 0 {
 		ollamaMessages = append(ollamaMessages, OllamaMessage{
-			Role:    msg.Role,
-			Content: msg.Content,
+			Role:    "system",
+			Content: passagesPrompt(passages),
 		})
 	}
 
-	// Call Ollama API via HTTP with streaming
-	ollamaReq := OllamaChatRequest{
-		Model:       "gpt-oss:20b-cloud",
-		Messages:    ollamaMessages,
-		Stream:      true,
-		Temperature: 0.1,
-	}
-
-	ollamaURL := os.Getenv("OLLAMA_URL")
-	if ollamaURL == "" {
-		ollamaURL = "http://localhost:11434"
-	}
-	ollamaAPIKey := os.Getenv("OLLAMA_API_KEY")
-	reqBody, _ := json.Marshal(ollamaReq)
-	httpReq, err := http.NewRequest("POST", ollamaURL+"/api/chat", bytes.NewBuffer(reqBody))
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create request"})
-		return
-	}
-	httpReq.Header.Set("Content-Type", "application/json")
-	if ollamaAPIKey != "" {
-		httpReq.Header.Set("Authorization", "Bearer "+ollamaAPIKey)
+	// Send the summary of older turns and the recent messages that fit in the history budget
+	history, fold := chatHistory(conversation, chatHistoryBudget(settings))
+	if conversation.Summary != "" {
+		ollamaMessages = append(ollamaMessages, OllamaMessage{
+			Role:    "system",
+			Content: "Resumo da conversa até aqui: " + conversation.Summary,
+		})
 	}
-	client := &http.Client{}
-	resp, err := client.Do(httpReq)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to call Ollama API"})
-		return
+	for _, msg := range history {
+		ollamaMessages = append(ollamaMessages, OllamaMessage{
+			Role:    msg.Role,
+			Content: msg.Content,
+		})
 	}
-	defer resp.Body.Close()
 
 	// Set up SSE
 	c.Header("Content-Type", "text/event-stream")
 	c.Header("Cache-Control", "no-cache")
 	c.Header("Connection", "keep-alive")
 
+	// Run tool calls server-side until the model answers, streaming its content as it arrives
 	var fullResponse strings.Builder
-	scanner := bufio.NewScanner(resp.Body)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if line == "" {
-			continue
+	var promptTokens, completionTokens int
+	toolContext := &chatToolContext{ctx: ctx}
+	for round := 0; round <= maxToolRounds; round++ {
+		tools := chatToolDefinitions()
+		if round == maxToolRounds {
+			// Last round: no tools, so the model has to answer with what it has
+			tools = nil
 		}
 
-		var chunk OllamaChatResponse
-		if err := json.Unmarshal([]byte(line), &chunk); err != nil {
-			continue // Skip invalid lines
+		result, err := streamOllamaRound(settings, ollamaMessages, tools, func(content string) {
+			fullResponse.WriteString(content)
+			// Send the content as is for real streaming
+			writeSSEData(c.Writer, content)
+			c.Writer.Flush()
+		})
+		if err != nil {
+			if !c.Writer.Written() {
+				c.Writer.Header().Del("Content-Type")
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to call Ollama API"})
+				return
+			}
+			break
+		}
+		promptTokens += result.PromptEvalCount
+		completionTokens += result.EvalCount
+		if len(result.Message.ToolCalls) == 0 {
+			break
 		}
 
-		content := chunk.Message.Content
-		fullResponse.WriteString(content)
+		ollamaMessages = append(ollamaMessages, result.Message)
+		for _, call := range result.Message.ToolCalls {
+			ollamaMessages = append(ollamaMessages, OllamaMessage{
+				Role:     "tool",
+				Content:  executeChatTool(toolContext, call),
+				ToolName: call.Function.Name,
+			})
+		}
+	}
 
-		// Send the content as is for real streaming
-		event := fmt.Sprintf("data: %s\n\n", content)
-		c.Writer.WriteString(event)
+	// Cite the content passages and web results referenced in the answer
+	if sources := formatPassages(passages, fullResponse.String()) + formatSources(toolContext.sources, fullResponse.String()); sources != "" {
+		fullResponse.WriteString(sources)
+		writeSSEData(c.Writer, sources)
 		c.Writer.Flush()
 	}
 
-	if err := scanner.Err(); err != nil {
-		// Handle error, but since streaming, maybe just log
+	// Append full assistant response to conversation, with the tokens used in all rounds
+	conversation.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+	assistantMessage := models.Message{
+		Role:         "assistant",
+		Content:      fullResponse.String(),
+		CreatedAt:    conversation.UpdatedAt,
+		Model:        settings.Model,
+		Tokens:       completionTokens,
+		PromptTokens: promptTokens,
 	}
-
-	// Append full assistant response to conversation
-	conversation.Messages = append(conversation.Messages, models.Message{
-		Role:    "assistant",
-		Content: fullResponse.String(),
-	})
-
-	// Save conversation back to Redis (if available)
-	if config.RedisClient != nil {
-		conversationJSONBytes, _ := json.Marshal(conversation)
-		config.RedisClient.Set(ctx, sessionKey, string(conversationJSONBytes), 0) // No expiration
+	conversation.Messages = append(conversation.Messages, assistantMessage)
+
+	// Save conversation to the store
+	if err := saveChatTurn(ctx, conversation, userMessage, assistantMessage); err != nil {
+		log.Println("⚠️  Erro ao salvar conversa:", err)
+	} else if fold > 0 {
+		// Summarize the older turns without holding up the next request
+		go summarizeInBackground(conversation, fold)
 	}
 
 	// End the stream
@@ -197,54 +353,114 @@ func ChatWithOllama(c *gin.Context) {
 	c.Writer.Flush()
 }
 
+// writeSSEData sends text as one SSE event with a data line per line of text, so blank lines in
+// the text don't end the event early; EventSource clients join the lines back with "\n"
+func writeSSEData(w io.StringWriter, text string) {
+	text = strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\r", "\n")
+	var b strings.Builder
+	for _, line := range strings.Split(text, "\n") {
+		b.WriteString("data: ")
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	w.WriteString(b.String())
+}
+
+// Attempts to save a turn when other requests keep writing to the same session
+const maxChatSaveAttempts = 3
+
+// saveChatTurn saves the conversation. The answer has already been streamed, so when another
+// request wrote to the session in the meantime the turn is appended after its messages instead of
+// being rejected
+func saveChatTurn(ctx context.Context, conversation models.Conversation, turn ...models.Message) error {
+	for attempt := 1; ; attempt++ {
+		err := config.ChatStore.Save(ctx, conversation)
+		if !errors.Is(err, chatstore.ErrConflict) || attempt == maxChatSaveAttempts {
+			return err
+		}
+
+		latest, found, err := config.ChatStore.Load(ctx, conversation.SessionID)
+		if err != nil {
+			return err
+		}
+		if !found || !ownsConversation(latest, conversation.UserID) {
+			// Deleted (or replaced) while the answer was streaming: don't bring it back
+			return chatstore.ErrConflict
+		}
+		latest.UpdatedAt = conversation.UpdatedAt
+		latest.Messages = append(latest.Messages, turn...)
+		conversation = latest
+	}
+}
+
 // GetChat godoc
 // @Summary Get chat conversation
-// @Description Retrieve the conversation for a session from Redis
+// @Description Retrieve the conversation for one of the user's sessions
 // @Tags chat
 // @Accept  json
 // @Produce  json
+// @Param X-User-ID header string true "User ID (not authenticated; set it from a trusted gateway)"
 // @Param session_id query string true "Session ID"
-// @Success 200 {object} map[string][]models.Message
+// @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
 // @Router /chat [get]
 func GetChat(c *gin.Context) {
+	userID, ok := currentChatUserID(c)
+	if !ok {
+		return
+	}
+
 	sessionID := c.Query("session_id")
 	if sessionID == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "session_id required"})
 		return
 	}
 
-	if config.RedisClient == nil {
-		c.JSON(http.StatusOK, gin.H{"messages": []models.Message{}})
+	conversation, found, err := config.ChatStore.Load(context.Background(), sessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load conversation"})
 		return
 	}
-
-	ctx := context.Background()
-	sessionKey := "chat:" + sessionID
-
-	conversationJSON, err := config.RedisClient.Get(ctx, sessionKey).Result()
-	if err != nil {
+	if !found {
 		c.JSON(http.StatusOK, gin.H{"messages": []models.Message{}})
 		return
 	}
+	if !ownsConversation(conversation, userID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
 
-	var conversation models.Conversation
-	json.Unmarshal([]byte(conversationJSON), &conversation)
-
-	c.JSON(http.StatusOK, gin.H{"messages": conversation.Messages})
+	c.JSON(http.StatusOK, gin.H{
+		"title":      conversation.Title,
+		"created_at": conversation.CreatedAt,
+		"updated_at": conversation.UpdatedAt,
+		"messages":   conversation.Messages,
+		"summary":    conversation.Summary,
+	})
 }
 
 // DeleteChat godoc
 // @Summary Delete chat conversation
-// @Description Delete the conversation for a session from Redis
+// @Description Delete one of the user's sessions
 // @Tags chat
 // @Accept  json
 // @Produce  json
+// @Param X-User-ID header string true "User ID (not authenticated; set it from a trusted gateway)"
 // @Param session_id query string true "Session ID"
 // @Success 200 {object} map[string]string
 // @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
 // @Router /chat [delete]
 func DeleteChat(c *gin.Context) {
+	userID, ok := currentChatUserID(c)
+	if !ok {
+		return
+	}
+
 	sessionID := c.Query("session_id")
 	if sessionID == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "session_id required"})
@@ -252,10 +468,21 @@ func DeleteChat(c *gin.Context) {
 	}
 
 	ctx := context.Background()
-	sessionKey := "chat:" + sessionID
+	conversation, found, err := config.ChatStore.Load(ctx, sessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load conversation"})
+		return
+	}
+	if found && !ownsConversation(conversation, userID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
 
-	if config.RedisClient != nil {
-		config.RedisClient.Del(ctx, sessionKey)
+	if found {
+		if err := config.ChatStore.Delete(ctx, conversation); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete conversation"})
+			return
+		}
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Conversation deleted"})
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"unicode/utf8"
+
+	"go-br-finance-api/chatstore"
+	"go-br-finance-api/config"
+	"go-br-finance-api/models"
+)
+
+const chatSummaryPrompt = "Resuma a conversa abaixo entre um usuário e um consultor financeiro em até 800 caracteres. Preserve os fatos sobre o usuário (objetivos, valores, prazos, perfil de risco), as perguntas em aberto e as conclusões. Responda apenas com o resumo, em português brasileiro."
+
+// estimateTokens approximates the token count of a message, at about four characters per token
+// plus the per-message overhead of the chat template
+func estimateTokens(content string) int {
+	return utf8.RuneCountInString(content)/4 + 4
+}
+
+func messagesTokens(messages []models.Message) int {
+	total := 0
+	for _, msg := range messages {
+		total += estimateTokens(msg.Content)
+	}
+	return total
+}
+
+// chatHistoryBudget is the number of tokens the summary and recent messages may use: CHAT_HISTORY_TOKENS,
+// limited to half the context window so the prompt, retrieved content, tools and answer still fit
+func chatHistoryBudget(settings chatSettings) int {
+	budget := envInt("CHAT_HISTORY_TOKENS", 3000)
+	if numCtx := settings.Options.NumCtx; numCtx > 0 && budget > numCtx/2 {
+		budget = numCtx / 2
+	}
+	return budget
+}
+
+// summarizeMessages folds the messages into the previous summary
+func summarizeMessages(summary string, messages []models.Message) (string, error) {
+	var b strings.Builder
+	if summary != "" {
+		fmt.Fprintf(&b, "Resumo anterior:\n%s\n\nNovas mensagens:\n", summary)
+	}
+	for _, msg := range messages {
+		role := "Usuário"
+		if msg.Role == "assistant" {
+			role = "Assistente"
+		}
+		fmt.Fprintf(&b, "%s: %s\n", role, msg.Content)
+	}
+
+	return ollamaComplete([]OllamaMessage{
+		{Role: "system", Content: chatSummaryPrompt},
+		{Role: "user", Content: b.String()},
+	})
+}
+
+// chatHistory returns the messages to send to the model within the token budget, along with how many
+// of the messages not yet summarized should be folded into the summary after this turn. When they
+// exceed the budget, the older turns are marked for folding, keeping the most recent ones within half
+// the budget. The current turn doesn't wait for the summary: the oldest messages are just left out
+// until the history fits, always keeping the last one.
+func chatHistory(conversation models.Conversation, budget int) (history []models.Message, fold int) {
+	pending := conversation.Messages[conversation.SummarizedCount:]
+	if estimateTokens(conversation.Summary)+messagesTokens(pending) > budget {
+		// Keep the most recent turns, starting at a user message
+		keep := len(pending) - 1
+		for i := len(pending) - 1; i > 0; i-- {
+			if messagesTokens(pending[i:]) > budget/2 {
+				break
+			}
+			if pending[i].Role == "user" {
+				keep = i
+			}
+		}
+		if keep > 0 {
+			fold = keep
+		}
+	}
+
+	for len(pending) > 1 && estimateTokens(conversation.Summary)+messagesTokens(pending) > budget {
+		pending = pending[1:]
+	}
+	return pending, fold
+}
+
+// foldSummary folds the first n messages not yet summarized into conversation.Summary
+func foldSummary(conversation *models.Conversation, n int, summarize func(string, []models.Message) (string, error)) error {
+	start := conversation.SummarizedCount
+	summary, err := summarize(conversation.Summary, conversation.Messages[start:start+n])
+	if err != nil {
+		return err
+	}
+	conversation.Summary = summary
+	conversation.SummarizedCount += n
+	return nil
+}
+
+// summarizeInBackground folds n messages into the summary after the answer has been streamed and
+// saves it, unless the session was deleted or summarized by another request in the meantime
+func summarizeInBackground(conversation models.Conversation, n int) {
+	from := conversation.SummarizedCount
+	if err := foldSummary(&conversation, n, summarizeMessages); err != nil {
+		log.Println("⚠️  Erro ao resumir conversa:", err)
+		return
+	}
+
+	ctx := context.Background()
+	latest, found, err := config.ChatStore.Load(ctx, conversation.SessionID)
+	if err != nil {
+		log.Println("⚠️  Erro ao carregar conversa para gravar o resumo:", err)
+		return
+	}
+	if !found || !ownsConversation(latest, conversation.UserID) || latest.SummarizedCount != from {
+		return
+	}
+
+	latest.Summary = conversation.Summary
+	latest.SummarizedCount = conversation.SummarizedCount
+	if err := config.ChatStore.Save(ctx, latest); err != nil && !errors.Is(err, chatstore.ErrConflict) {
+		// On a conflict the next turn folds the messages again
+		log.Println("⚠️  Erro ao gravar resumo da conversa:", err)
+	}
+}
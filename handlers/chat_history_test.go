@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go-br-finance-api/models"
+)
+
+// chatMessages cria mensagens alternando usuário e assistente, cada uma com o número de tokens
+// estimados indicado (incluindo os 4 de overhead)
+func chatMessages(tokens ...int) []models.Message {
+	messages := make([]models.Message, len(tokens))
+	for i, n := range tokens {
+		role := "user"
+		if i%2 == 1 {
+			role = "assistant"
+		}
+		messages[i] = models.Message{Role: role, Content: strings.Repeat("abcd", n-4)}
+	}
+	return messages
+}
+
+func TestChatHistory(t *testing.T) {
+	tests := []struct {
+		name         string
+		conversation models.Conversation
+		budget       int
+		wantFirst    int // índice em Messages da primeira mensagem enviada
+		wantFold     int
+	}{
+		{
+			name:         "cabe no orçamento",
+			conversation: models.Conversation{Messages: chatMessages(10, 10, 10, 10)},
+			budget:       100,
+			wantFirst:    0,
+			wantFold:     0,
+		},
+		{
+			name:         "resume os turnos antigos e trunca até caber",
+			conversation: models.Conversation{Messages: chatMessages(30, 30, 20, 20, 10)},
+			budget:       100,
+			wantFirst:    1,
+			wantFold:     2,
+		},
+		{
+			name:         "mantém os recentes a partir de uma mensagem do usuário",
+			conversation: models.Conversation{Messages: chatMessages(40, 40, 40, 10, 30)},
+			budget:       100,
+			wantFirst:    2,
+			wantFold:     4,
+		},
+		{
+			name:         "ignora as mensagens já resumidas",
+			conversation: models.Conversation{Messages: chatMessages(500, 500, 10, 10, 10), Summary: "resumo", SummarizedCount: 2},
+			budget:       100,
+			wantFirst:    2,
+			wantFold:     0,
+		},
+		{
+			name:         "mensagem única grande demais",
+			conversation: models.Conversation{Messages: chatMessages(500)},
+			budget:       100,
+			wantFirst:    0,
+			wantFold:     0,
+		},
+		{
+			name:         "última mensagem sempre enviada",
+			conversation: models.Conversation{Messages: chatMessages(10, 10, 500)},
+			budget:       100,
+			wantFirst:    2,
+			wantFold:     2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			history, fold := chatHistory(tt.conversation, tt.budget)
+			want := tt.conversation.Messages[tt.wantFirst:]
+			if !reflect.DeepEqual(history, want) || fold != tt.wantFold {
+				t.Errorf("chatHistory = %d mensagens, fold %d; esperado %d mensagens, fold %d", len(history), fold, len(want), tt.wantFold)
+			}
+		})
+	}
+}
+
+func TestFoldSummary(t *testing.T) {
+	messages := chatMessages(10, 10, 10, 10)
+
+	t.Run("acrescenta ao resumo", func(t *testing.T) {
+		conversation := models.Conversation{Messages: messages, Summary: "anterior", SummarizedCount: 1}
+		var got []models.Message
+		err := foldSummary(&conversation, 2, func(summary string, msgs []models.Message) (string, error) {
+			got = msgs
+			return summary + " + novo", nil
+		})
+		if err != nil {
+			t.Fatalf("foldSummary: %v", err)
+		}
+		if !reflect.DeepEqual(got, messages[1:3]) {
+			t.Errorf("mensagens resumidas = %v, esperado %v", got, messages[1:3])
+		}
+		if conversation.Summary != "anterior + novo" || conversation.SummarizedCount != 3 {
+			t.Errorf("resumo = %q, %d resumidas; esperado %q, 3", conversation.Summary, conversation.SummarizedCount, "anterior + novo")
+		}
+	})
+
+	t.Run("erro mantém a conversa", func(t *testing.T) {
+		conversation := models.Conversation{Messages: messages, Summary: "anterior", SummarizedCount: 1}
+		err := foldSummary(&conversation, 2, func(string, []models.Message) (string, error) {
+			return "", errors.New("ollama fora do ar")
+		})
+		if err == nil {
+			t.Fatal("foldSummary: esperado erro")
+		}
+		if conversation.Summary != "anterior" || conversation.SummarizedCount != 1 {
+			t.Errorf("resumo = %q, %d resumidas; esperado inalterado", conversation.Summary, conversation.SummarizedCount)
+		}
+	})
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"go-br-finance-api/config"
+	"go-br-finance-api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+var profileNamePattern = regexp.MustCompile(`^[a-z0-9_-]{2,50}$`)
+
+func validateChatProfile(c *gin.Context, perfil models.PerfilChat) bool {
+	if len(perfil.SystemPrompt) < 10 || len(perfil.SystemPrompt) > 4000 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "system_prompt must have between 10 and 4000 characters"})
+		return false
+	}
+	if perfil.Temperatura != nil && (*perfil.Temperatura < 0 || *perfil.Temperatura > maxChatTemperature) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "temperatura must be between 0 and 1.5"})
+		return false
+	}
+	if perfil.MaxTokens != nil && (*perfil.MaxTokens < 1 || *perfil.MaxTokens > maxChatTokens) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("max_tokens must be between 1 and %d", maxChatTokens)})
+		return false
+	}
+	if perfil.ContextWindow != nil && (*perfil.ContextWindow < minChatContextWindow || *perfil.ContextWindow > maxChatContextWindow) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("context_window must be between %d and %d", minChatContextWindow, maxChatContextWindow)})
+		return false
+	}
+	return true
+}
+
+// GetChatProfiles godoc
+// @Summary List chat profiles
+// @Description List the named prompt profiles that can be used in POST /chat
+// @Tags chat
+// @Accept  json
+// @Produce  json
+// @Success 200 {array} models.PerfilChat
+// @Failure 500 {object} map[string]string
+// @Router /chat/profiles [get]
+func GetChatProfiles(c *gin.Context) {
+	perfis := []models.PerfilChat{}
+	if err := config.DB.Select(&perfis, "SELECT * FROM chat_perfis ORDER BY nome"); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list profiles"})
+		return
+	}
+
+	c.JSON(http.StatusOK, perfis)
+}
+
+// CreateChatProfile godoc
+// @Summary Create chat profile
+// @Description Create a named prompt profile, optionally with its own model, temperature, max_tokens and context_window (admin only)
+// @Tags chat
+// @Accept  json
+// @Produce  json
+// @Param X-Admin-Token header string true "Admin token"
+// @Param request body models.PerfilChat true "Profile"
+// @Success 201 {object} models.PerfilChat
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /chat/profiles [post]
+func CreateChatProfile(c *gin.Context) {
+	var perfil models.PerfilChat
+	if err := c.ShouldBindJSON(&perfil); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "details": err.Error()})
+		return
+	}
+
+	if !profileNamePattern.MatchString(perfil.Nome) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "nome must have 2 to 50 lowercase letters, digits, '-' or '_'"})
+		return
+	}
+	if !validateChatProfile(c, perfil) {
+		return
+	}
+
+	query := `INSERT INTO chat_perfis (nome, descricao, system_prompt, modelo, temperatura, max_tokens, context_window)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) ON CONFLICT (nome) DO NOTHING RETURNING id`
+	err := config.DB.QueryRow(query, perfil.Nome, perfil.Descricao, perfil.SystemPrompt, perfil.Modelo, perfil.Temperatura,
+		perfil.MaxTokens, perfil.ContextWindow).Scan(&perfil.ID)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusConflict, gin.H{"error": "Profile already exists"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create profile"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, perfil)
+}
+
+// UpdateChatProfile godoc
+// @Summary Update chat profile
+// @Description Update the prompt, model, temperature, max_tokens and context_window of a profile (admin only)
+// @Tags chat
+// @Accept  json
+// @Produce  json
+// @Param X-Admin-Token header string true "Admin token"
+// @Param nome path string true "Profile name"
+// @Param request body models.PerfilChat true "Profile"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /chat/profiles/{nome} [put]
+func UpdateChatProfile(c *gin.Context) {
+	var perfil models.PerfilChat
+	if err := c.ShouldBindJSON(&perfil); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "details": err.Error()})
+		return
+	}
+
+	if !validateChatProfile(c, perfil) {
+		return
+	}
+
+	query := `UPDATE chat_perfis SET descricao = $1, system_prompt = $2, modelo = $3, temperatura = $4, max_tokens = $5,
+			context_window = $6, updated_at = CURRENT_TIMESTAMP
+		WHERE nome = $7`
+	result, err := config.DB.Exec(query, perfil.Descricao, perfil.SystemPrompt, perfil.Modelo, perfil.Temperatura,
+		perfil.MaxTokens, perfil.ContextWindow, c.Param("nome"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update profile"})
+		return
+	}
+
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Profile not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Profile updated"})
+}
+
+// DeleteChatProfile godoc
+// @Summary Delete chat profile
+// @Description Delete a prompt profile (admin only)
+// @Tags chat
+// @Accept  json
+// @Produce  json
+// @Param X-Admin-Token header string true "Admin token"
+// @Param nome path string true "Profile name"
+// @Success 200 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /chat/profiles/{nome} [delete]
+func DeleteChatProfile(c *gin.Context) {
+	result, err := config.DB.Exec("DELETE FROM chat_perfis WHERE nome = $1", c.Param("nome"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete profile"})
+		return
+	}
+
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Profile not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Profile deleted"})
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"go-br-finance-api/chatstore"
+	"go-br-finance-api/config"
+	"go-br-finance-api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxChatTitleLength = 60
+
+type RenameChatSessionRequest struct {
+	Title string `json:"title" binding:"required"`
+}
+
+// chatTitle derives the session title from its first message
+func chatTitle(message string) string {
+	title := strings.Join(strings.Fields(message), " ")
+	if utf8.RuneCountInString(title) > maxChatTitleLength {
+		title = strings.TrimSpace(string([]rune(title)[:maxChatTitleLength-1])) + "…"
+	}
+	return title
+}
+
+// ownsConversation reports whether the session belongs to the user. Sessions saved before
+// sessions had owners belong to no one, so nobody can read or delete them.
+func ownsConversation(conversation models.Conversation, userID string) bool {
+	return conversation.UserID != "" && conversation.UserID == userID
+}
+
+// GetChatSessions godoc
+// @Summary List chat sessions
+// @Description List the user's chat sessions with titles and timestamps, most recent first
+// @Tags chat
+// @Accept  json
+// @Produce  json
+// @Param X-User-ID header string true "User ID (not authenticated; set it from a trusted gateway)"
+// @Success 200 {array} models.ChatSession
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /chat/sessions [get]
+func GetChatSessions(c *gin.Context) {
+	userID, ok := currentChatUserID(c)
+	if !ok {
+		return
+	}
+
+	sessions, err := config.ChatStore.List(context.Background(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// RenameChatSession godoc
+// @Summary Rename chat session
+// @Description Change the title of one of the user's chat sessions
+// @Tags chat
+// @Accept  json
+// @Produce  json
+// @Param X-User-ID header string true "User ID (not authenticated; set it from a trusted gateway)"
+// @Param id path string true "Session ID"
+// @Param request body RenameChatSessionRequest true "New title"
+// @Success 200 {object} models.ChatSession
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /chat/sessions/{id} [patch]
+func RenameChatSession(c *gin.Context) {
+	userID, ok := currentChatUserID(c)
+	if !ok {
+		return
+	}
+
+	var req RenameChatSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "details": err.Error()})
+		return
+	}
+
+	title := strings.TrimSpace(req.Title)
+	if title == "" || utf8.RuneCountInString(title) > maxChatTitleLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "title must have between 1 and 60 characters"})
+		return
+	}
+
+	ctx := context.Background()
+	conversation, found, err := config.ChatStore.Load(ctx, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load session"})
+		return
+	}
+	if !found || conversation.UserID != userID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+
+	conversation.Title = title
+	err = config.ChatStore.Save(ctx, conversation)
+	if errors.Is(err, chatstore.ErrConflict) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Session was updated by another request, try again"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rename session"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ChatSession{
+		SessionID:    conversation.SessionID,
+		Title:        conversation.Title,
+		CreatedAt:    conversation.CreatedAt,
+		UpdatedAt:    conversation.UpdatedAt,
+		MessageCount: len(conversation.Messages),
+	})
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"go-br-finance-api/config"
+	"go-br-finance-api/models"
+)
+
+const (
+	defaultChatModel        = "gpt-oss:20b-cloud"
+	defaultChatSystemPrompt = "Você é um consultor financeiro brasileiro. Forneça conselhos em português brasileiro. Mantenha as respostas concisas, com no máximo 300 caracteres. Responda sempre em português brasileiro, nunca em inglês."
+
+	// chatToolInstructions is appended to every system prompt, whatever the profile
+	chatToolInstructions = "Para cotações, câmbio, taxas (Selic, CDI, IPCA) e simulações de investimento, use as ferramentas disponíveis em vez de estimar valores. Ao usar resultados da busca na web, cite-os pelo número, ex: [1]."
+
+	// Limits for the parameters a request can override
+	maxChatTemperature   = 1.5
+	maxChatTokens        = 4096
+	minChatContextWindow = 512
+	maxChatContextWindow = 131072
+)
+
+// OllamaOptions are the model parameters sent to Ollama
+type OllamaOptions struct {
+	Temperature float64 `json:"temperature"`
+	NumPredict  int     `json:"num_predict,omitempty"`
+	NumCtx      int     `json:"num_ctx,omitempty"`
+}
+
+// errInvalidChatSettings is returned when the request asks for a profile or parameter it cannot use
+var errInvalidChatSettings = errors.New("invalid chat settings")
+
+// chatSettings are the model and prompt used for one chat request
+type chatSettings struct {
+	Model        string
+	Profile      string
+	SystemPrompt string
+	Options      OllamaOptions
+}
+
+// defaultChatSettings reads the deployment settings from the environment:
+// OLLAMA_MODEL, CHAT_TEMPERATURE, CHAT_MAX_TOKENS, CHAT_CONTEXT_WINDOW and CHAT_SYSTEM_PROMPT
+func defaultChatSettings() chatSettings {
+	settings := chatSettings{
+		Model:        os.Getenv("OLLAMA_MODEL"),
+		SystemPrompt: os.Getenv("CHAT_SYSTEM_PROMPT"),
+		Options: OllamaOptions{
+			Temperature: envFloat("CHAT_TEMPERATURE", 0.1),
+			NumPredict:  envInt("CHAT_MAX_TOKENS", 0),
+			NumCtx:      envInt("CHAT_CONTEXT_WINDOW", 0),
+		},
+	}
+	if settings.Model == "" {
+		settings.Model = defaultChatModel
+	}
+	if settings.SystemPrompt == "" {
+		settings.SystemPrompt = defaultChatSystemPrompt
+	}
+	return settings
+}
+
+// chatAllowedModels returns the models a request may choose: the deployment model plus CHAT_ALLOWED_MODELS
+func chatAllowedModels() []string {
+	allowed := []string{defaultChatSettings().Model}
+	for _, m := range strings.Split(os.Getenv("CHAT_ALLOWED_MODELS"), ",") {
+		if m = strings.TrimSpace(m); m != "" && m != allowed[0] {
+			allowed = append(allowed, m)
+		}
+	}
+	return allowed
+}
+
+func loadChatProfile(nome string) (models.PerfilChat, error) {
+	var perfil models.PerfilChat
+	err := config.DB.Get(&perfil, "SELECT * FROM chat_perfis WHERE nome = $1", nome)
+	return perfil, err
+}
+
+// resolveChatSettings applies, in order, the deployment settings, the profile (from the request
+// or CHAT_PROFILE) and the parameters the request overrides
+func resolveChatSettings(req ChatRequest) (chatSettings, error) {
+	settings := defaultChatSettings()
+
+	profile := req.Profile
+	if profile == "" {
+		profile = os.Getenv("CHAT_PROFILE")
+	}
+	if profile != "" {
+		perfil, err := loadChatProfile(profile)
+		if errors.Is(err, sql.ErrNoRows) {
+			return settings, fmt.Errorf("%w: unknown profile %q", errInvalidChatSettings, profile)
+		}
+		if err != nil {
+			return settings, err
+		}
+		settings.Profile = perfil.Nome
+		settings.SystemPrompt = perfil.SystemPrompt
+		if perfil.Modelo != nil && *perfil.Modelo != "" {
+			settings.Model = *perfil.Modelo
+		}
+		if perfil.Temperatura != nil {
+			settings.Options.Temperature = *perfil.Temperatura
+		}
+		if perfil.MaxTokens != nil {
+			settings.Options.NumPredict = *perfil.MaxTokens
+		}
+		if perfil.ContextWindow != nil {
+			settings.Options.NumCtx = *perfil.ContextWindow
+		}
+	}
+
+	if req.Model != "" {
+		allowed := false
+		for _, m := range chatAllowedModels() {
+			if m == req.Model {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return settings, fmt.Errorf("%w: model %q is not allowed", errInvalidChatSettings, req.Model)
+		}
+		settings.Model = req.Model
+	}
+
+	if req.Temperature != nil {
+		if *req.Temperature < 0 || *req.Temperature > maxChatTemperature {
+			return settings, fmt.Errorf("%w: temperature must be between 0 and %.1f", errInvalidChatSettings, maxChatTemperature)
+		}
+		settings.Options.Temperature = *req.Temperature
+	}
+
+	if req.MaxTokens != nil {
+		if *req.MaxTokens < 1 || *req.MaxTokens > maxChatTokens {
+			return settings, fmt.Errorf("%w: max_tokens must be between 1 and %d", errInvalidChatSettings, maxChatTokens)
+		}
+		settings.Options.NumPredict = *req.MaxTokens
+	}
+
+	if req.ContextWindow != nil {
+		if *req.ContextWindow < minChatContextWindow || *req.ContextWindow > maxChatContextWindow {
+			return settings, fmt.Errorf("%w: context_window must be between %d and %d", errInvalidChatSettings, minChatContextWindow, maxChatContextWindow)
+		}
+		settings.Options.NumCtx = *req.ContextWindow
+	}
+
+	return settings, nil
+}
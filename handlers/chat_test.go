@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+)
+
+// readSSEData junta as linhas data: de cada evento como um cliente EventSource
+func readSSEData(stream string) []string {
+	var events, data []string
+	for _, line := range strings.Split(stream, "\n") {
+		switch {
+		case line == "":
+			if data != nil {
+				events = append(events, strings.Join(data, "\n"))
+				data = nil
+			}
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	return events
+}
+
+func TestWriteSSEData(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "uma linha", text: "Olá", want: "Olá"},
+		{name: "fontes após linha em branco", text: "\n\nFontes:\n[1] Selic hoje - https://example.com/selic", want: "\n\nFontes:\n[1] Selic hoje - https://example.com/selic"},
+		{name: "conteúdo citado", text: "\n\nConteúdo citado:\n[R1] Recomendação #3: PETR4", want: "\n\nConteúdo citado:\n[R1] Recomendação #3: PETR4"},
+		{name: "quebras de linha CRLF", text: "a\r\nb\rc", want: "a\nb\nc"},
+		{name: "vazio", text: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			writeSSEData(&b, tt.text)
+			events := readSSEData(b.String())
+			if len(events) != 1 || events[0] != tt.want {
+				t.Errorf("writeSSEData(%q) = %q, lido como %q; esperado um evento %q", tt.text, b.String(), events, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go-br-finance-api/config"
+	"go-br-finance-api/websearch"
+)
+
+// Maximum number of tool-calling rounds before the model must answer
+const maxToolRounds = 5
+
+type OllamaTool struct {
+	Type     string             `json:"type"`
+	Function OllamaToolFunction `json:"function"`
+}
+
+type OllamaToolFunction struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Parameters  map[string]interface{} `json:"parameters"`
+}
+
+type OllamaToolCall struct {
+	Function struct {
+		Name      string                 `json:"name"`
+		Arguments map[string]interface{} `json:"arguments"`
+	} `json:"function"`
+}
+
+// Number of web results returned to the model per search
+const webSearchLimit = 5
+
+// chatToolContext holds per-request state shared by the tool calls, such as the cited sources
+type chatToolContext struct {
+	ctx     context.Context
+	sources []websearch.Result
+}
+
+type chatTool struct {
+	description string
+	properties  map[string]interface{}
+	required    []string
+	run         func(tc *chatToolContext, args map[string]interface{}) (interface{}, error)
+}
+
+// chatTools are the functions the model can call, backed by the API's own data
+var chatTools = map[string]chatTool{
+	"get_stock_quote": {
+		description: "Cotação atual de uma ação, FII, ETF ou BDR da B3 pelo código de negociação (ex: PETR4)",
+		properties: map[string]interface{}{
+			"symbol": map[string]string{"type": "string", "description": "Código de negociação, ex: PETR4"},
+		},
+		required: []string{"symbol"},
+		run:      toolStockQuote,
+	},
+	"convert_currency": {
+		description: "Converte um valor entre moedas (ex: USD, EUR, BRL) pela cotação atual",
+		properties: map[string]interface{}{
+			"from":   map[string]string{"type": "string", "description": "Moeda de origem, ex: USD"},
+			"to":     map[string]string{"type": "string", "description": "Moeda de destino, ex: BRL"},
+			"amount": map[string]string{"type": "number", "description": "Valor a converter"},
+		},
+		required: []string{"from", "to", "amount"},
+		run:      toolConvertCurrency,
+	},
+	"simulate_investment": {
+		description: "Projeta um investimento com juros compostos e aportes mensais",
+		properties: map[string]interface{}{
+			"principal":       map[string]string{"type": "number", "description": "Valor inicial em reais"},
+			"monthly_deposit": map[string]string{"type": "number", "description": "Aporte mensal em reais"},
+			"annual_rate":     map[string]string{"type": "number", "description": "Taxa anual em %, ex: 10.5"},
+			"years":           map[string]string{"type": "integer", "description": "Prazo em anos (1 a 50)"},
+		},
+		required: []string{"monthly_deposit", "annual_rate", "years"},
+		run:      toolSimulateInvestment,
+	},
+	"get_rates": {
+		description: "Taxas atuais do Brasil: Selic, CDI e IPCA",
+		properties:  map[string]interface{}{},
+		run: func(tc *chatToolContext, args map[string]interface{}) (interface{}, error) {
+			return fetchTaxas()
+		},
+	},
+	"web_search": {
+		description: "Busca notícias e informações atuais na web. Cite os resultados usados pelo número, ex: [1]",
+		properties: map[string]interface{}{
+			"query": map[string]string{"type": "string", "description": "Termos da busca"},
+		},
+		required: []string{"query"},
+		run:      toolWebSearch,
+	},
+}
+
+// chatToolDefinitions returns the tool list in the format expected by Ollama's tools field
+func chatToolDefinitions() []OllamaTool {
+	tools := make([]OllamaTool, 0, len(chatTools))
+	names := []string{"get_stock_quote", "convert_currency", "simulate_investment", "get_rates"}
+	if config.SearchProvider != nil {
+		names = append(names, "web_search")
+	}
+	for _, name := range names {
+		tool := chatTools[name]
+		required := tool.required
+		if required == nil {
+			required = []string{}
+		}
+		tools = append(tools, OllamaTool{
+			Type: "function",
+			Function: OllamaToolFunction{
+				Name:        name,
+				Description: tool.description,
+				Parameters: map[string]interface{}{
+					"type":       "object",
+					"properties": tool.properties,
+					"required":   required,
+				},
+			},
+		})
+	}
+	return tools
+}
+
+// executeChatTool runs a tool call and returns its JSON result; errors are returned to the model as text
+func executeChatTool(tc *chatToolContext, call OllamaToolCall) string {
+	tool, ok := chatTools[call.Function.Name]
+	if !ok {
+		return fmt.Sprintf(`{"error": "unknown tool %s"}`, call.Function.Name)
+	}
+
+	result, err := tool.run(tc, call.Function.Arguments)
+	if err != nil {
+		errJSON, _ := json.Marshal(map[string]string{"error": err.Error()})
+		return string(errJSON)
+	}
+
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return `{"error": "failed to encode result"}`
+	}
+	return string(resultJSON)
+}
+
+func stringArg(args map[string]interface{}, name string) string {
+	if v, ok := args[name].(string); ok {
+		return strings.TrimSpace(v)
+	}
+	return ""
+}
+
+// numberArg accepts numbers sent either as JSON numbers or as strings
+func numberArg(args map[string]interface{}, name string) (float64, error) {
+	switch v := args[name].(type) {
+	case float64:
+		return v, nil
+	case string:
+		return strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(v), ",", "."), 64)
+	case nil:
+		return 0, fmt.Errorf("missing argument %s", name)
+	default:
+		return 0, fmt.Errorf("invalid argument %s", name)
+	}
+}
+
+func toolStockQuote(tc *chatToolContext, args map[string]interface{}) (interface{}, error) {
+	symbol := strings.ToUpper(strings.TrimSpace(stringArg(args, "symbol")))
+	if symbol == "" {
+		return nil, fmt.Errorf("symbol is required")
+	}
+	if !validTicker(symbol) {
+		return nil, fmt.Errorf("invalid symbol %q", symbol)
+	}
+
+	if stocks, err := loadStocks(tc.ctx); err == nil {
+		for _, stock := range stocks {
+			if stock.Symbol == symbol {
+				return stock, nil
+			}
+		}
+	}
+
+	quotes, err := fetchQuotes([]string{symbol})
+	if err != nil || len(quotes) == 0 {
+		return nil, fmt.Errorf("quote for %s not found", symbol)
+	}
+	return quotes[0], nil
+}
+
+func toolConvertCurrency(tc *chatToolContext, args map[string]interface{}) (interface{}, error) {
+	from := strings.ToUpper(strings.TrimSpace(stringArg(args, "from")))
+	to := strings.ToUpper(strings.TrimSpace(stringArg(args, "to")))
+	amount, err := numberArg(args, "amount")
+	if err != nil {
+		return nil, err
+	}
+	if from == "" || to == "" || amount <= 0 {
+		return nil, fmt.Errorf("from, to and a positive amount are required")
+	}
+	if !validCurrency(from) || !validCurrency(to) {
+		return nil, fmt.Errorf("currencies must be three-letter codes such as USD")
+	}
+
+	// Both currencies are quoted in BRL, so any pair goes through the real
+	toBRL := func(moeda string) (float64, error) {
+		if moeda == "BRL" {
+			return 1, nil
+		}
+		return fetchExchangeRate(moeda)
+	}
+	fromRate, err := toBRL(from)
+	if err != nil {
+		return nil, err
+	}
+	toRate, err := toBRL(to)
+	if err != nil {
+		return nil, err
+	}
+
+	rate := fromRate / toRate
+	return CurrencyConversion{From: from, To: to, Amount: amount, Result: amount * rate, Rate: rate}, nil
+}
+
+func toolSimulateInvestment(tc *chatToolContext, args map[string]interface{}) (interface{}, error) {
+	principal, _ := numberArg(args, "principal")
+	monthlyDeposit, err := numberArg(args, "monthly_deposit")
+	if err != nil {
+		return nil, err
+	}
+	annualRate, err := numberArg(args, "annual_rate")
+	if err != nil {
+		return nil, err
+	}
+	years, err := numberArg(args, "years")
+	if err != nil {
+		return nil, err
+	}
+
+	if principal < 0 || monthlyDeposit < 0 || annualRate < 0 || annualRate > 100 || years < 1 || years > 50 {
+		return nil, fmt.Errorf("values must be non-negative, annual_rate between 0 and 100 and years between 1 and 50")
+	}
+	return simulateInvestment(principal, monthlyDeposit, annualRate, int(years)), nil
+}
+
+type numberedResult struct {
+	N int `json:"n"`
+	websearch.Result
+}
+
+func toolWebSearch(tc *chatToolContext, args map[string]interface{}) (interface{}, error) {
+	query := stringArg(args, "query")
+	if query == "" {
+		return nil, fmt.Errorf("query is required")
+	}
+	if config.SearchProvider == nil {
+		return nil, fmt.Errorf("web search is not configured")
+	}
+
+	results, err := config.SearchProvider.Search(tc.ctx, query, webSearchLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Numbering continues across searches so citations stay unique within the answer
+	numbered := make([]numberedResult, 0, len(results))
+	for _, r := range results {
+		tc.sources = append(tc.sources, r)
+		numbered = append(numbered, numberedResult{N: len(tc.sources), Result: r})
+	}
+	return numbered, nil
+}
+
+// formatSources lists the web results cited in the answer (as [n]) as references appended to it
+func formatSources(sources []websearch.Result, answer string) string {
+	var b strings.Builder
+	for i, s := range sources {
+		label := fmt.Sprintf("[%d]", i+1)
+		if !strings.Contains(answer, label) {
+			continue
+		}
+		if b.Len() == 0 {
+			b.WriteString("\n\nFontes:")
+		}
+		fmt.Fprintf(&b, "\n%s %s - %s", label, s.Title, s.URL)
+	}
+	return b.String()
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"go-br-finance-api/config"
+	"go-br-finance-api/websearch"
+)
+
+func TestWebSearchCitedSources(t *testing.T) {
+	previous := config.SearchProvider
+	defer func() { config.SearchProvider = previous }()
+	config.SearchProvider = &websearch.FakeProvider{Results: []websearch.Result{
+		{Title: "Selic hoje", URL: "https://example.com/selic"},
+		{Title: "CDI hoje", URL: "https://example.com/cdi"},
+	}}
+
+	// Duas buscas: a numeração continua entre elas (1 e 2, depois 3 e 4)
+	tc := &chatToolContext{ctx: context.Background()}
+	for i, query := range []string{"selic", "cdi"} {
+		result, err := toolWebSearch(tc, map[string]interface{}{"query": query})
+		if err != nil {
+			t.Fatalf("toolWebSearch(%q): %v", query, err)
+		}
+		for j, r := range result.([]numberedResult) {
+			if want := 2*i + j + 1; r.N != want {
+				t.Errorf("busca %q: resultado %d numerado %d, esperado %d", query, j, r.N, want)
+			}
+		}
+	}
+
+	tests := []struct {
+		name    string
+		answer  string
+		want    []string
+		notWant []string
+	}{
+		{name: "sem citações", answer: "A Selic está em 10,5%.", want: nil, notWant: []string{"Fontes:"}},
+		{name: "uma citação", answer: "A Selic está em 10,5% [1].", want: []string{"[1] Selic hoje - https://example.com/selic"}, notWant: []string{"[2]", "[3]", "[4]"}},
+		{name: "citações da segunda busca", answer: "O CDI acompanha a Selic [4][3].", want: []string{"[3] Selic hoje", "[4] CDI hoje"}, notWant: []string{"[1]", "[2]"}},
+		{name: "rótulo de conteúdo não conta", answer: "Veja [R1].", want: nil, notWant: []string{"Fontes:"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := formatSources(tc.sources, tt.answer)
+			if tt.want == nil && got != "" {
+				t.Fatalf("formatSources = %q, esperado vazio", got)
+			}
+			for _, w := range tt.want {
+				if !strings.Contains(got, w) {
+					t.Errorf("formatSources = %q, esperado conter %q", got, w)
+				}
+			}
+			for _, nw := range tt.notWant {
+				if strings.Contains(got, nw) {
+					t.Errorf("formatSources = %q, não deveria conter %q", got, nw)
+				}
+			}
+		})
+	}
+}
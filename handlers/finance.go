@@ -2,12 +2,15 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
 	"go-br-finance-api/cache"
 	"go-br-finance-api/config"
 	"go-br-finance-api/models"
+	"go-br-finance-api/notify"
 
 	"github.com/gin-gonic/gin"
 )
@@ -22,6 +25,28 @@ type Resposta struct {
 	Recomendacoes []models.Recomendacao `json:"recomendacoes"`
 }
 
+// fetchTaxas busca as taxas (Selic, CDI, IPCA) na Brasil API, com cache de 30 minutos
+func fetchTaxas() ([]Taxa, error) {
+	cacheKey := "brasil_api_taxas"
+	if cachedData, found := cache.GlobalCache.Get(cacheKey); found {
+		return cachedData.([]Taxa), nil
+	}
+
+	resp, err := http.Get("https://brasilapi.com.br/api/taxas/v1")
+	if err != nil {
+		return nil, errors.New("Não foi possível buscar taxas")
+	}
+	defer resp.Body.Close()
+
+	var taxas []Taxa
+	if err := json.NewDecoder(resp.Body).Decode(&taxas); err != nil {
+		return nil, errors.New("Erro ao decodificar taxas")
+	}
+
+	cache.GlobalCache.Set(cacheKey, taxas, 30*time.Minute)
+	return taxas, nil
+}
+
 // GetInformacoesFinanceiras godoc
 // @Summary Obter informações financeiras
 // @Description Obtém informações financeiras incluindo taxas e recomendações
@@ -32,33 +57,15 @@ type Resposta struct {
 // @Failure 500 {object} map[string]string
 // @Router /informacoes-financeiras [get]
 func GetInformacoesFinanceiras(c *gin.Context) {
-	var taxas []Taxa
-
-	// Tentar obter taxas do cache primeiro
-	cacheKey := "brasil_api_taxas"
-	if cachedData, found := cache.GlobalCache.Get(cacheKey); found {
-		taxas = cachedData.([]Taxa)
-	} else {
-		// Buscar taxas da Brasil API
-		resp, err := http.Get("https://brasilapi.com.br/api/taxas/v1")
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"erro": "Não foi possível buscar taxas"})
-			return
-		}
-		defer resp.Body.Close()
-
-		if err := json.NewDecoder(resp.Body).Decode(&taxas); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao decodificar taxas"})
-			return
-		}
-
-		// Cache por 30 minutos
-		cache.GlobalCache.Set(cacheKey, taxas, 30*time.Minute)
+	taxas, err := fetchTaxas()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": err.Error()})
+		return
 	}
 
 	// Buscar recomendações no Postgres
 	var recomendacoes []models.Recomendacao
-	err := config.DB.Select(&recomendacoes, "SELECT * FROM recomendacoes_financeiras")
+	err = config.DB.Select(&recomendacoes, "SELECT * FROM recomendacoes_financeiras")
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar recomendações"})
 		return
@@ -130,6 +137,13 @@ func CreateRecomendacao(c *gin.Context) {
 		return
 	}
 
+	requestReindex()
+	go notifyTopic(models.TopicoRecomendacoes, notify.Notification{
+		Title: "Nova recomendação",
+		Body:  recomendacao.Titulo,
+		Data:  map[string]string{"tipo": "recomendacao", "recomendacao_id": strconv.Itoa(id)},
+	})
+
 	c.JSON(http.StatusCreated, gin.H{"mensagem": "Recomendação criada com sucesso", "id": id})
 }
 
@@ -180,6 +194,7 @@ func UpdateRecomendacao(c *gin.Context) {
 		return
 	}
 
+	requestReindex()
 	c.JSON(http.StatusOK, gin.H{"mensagem": "Recomendação atualizada com sucesso"})
 }
 
@@ -212,5 +227,6 @@ func DeleteRecomendacao(c *gin.Context) {
 		return
 	}
 
+	requestReindex()
 	c.JSON(http.StatusOK, gin.H{"mensagem": "Recomendação deletada com sucesso"})
 }
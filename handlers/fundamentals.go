@@ -0,0 +1,358 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"go-br-finance-api/cache"
+	"go-br-finance-api/config"
+	"go-br-finance-api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FundamentalsResponse traz os indicadores por trimestre, do mais recente para o mais antigo. Os
+// trimestres divulgados vêm dos demonstrativos trimestrais (receita, lucro, patrimônio, margem e ROE);
+// os múltiplos de mercado (P/L, P/VP, DY, EV/EBITDA) são os atuais, gravados no trimestre corrente
+type FundamentalsResponse struct {
+	Symbol    string              `json:"symbol"`
+	Historico []models.Fundamento `json:"historico"`
+}
+
+type brapiFundamentalsResponse struct {
+	Results []struct {
+		Symbol               string   `json:"symbol"`
+		PriceEarnings        *float64 `json:"priceEarnings"`
+		DefaultKeyStatistics struct {
+			PriceToBook        *float64 `json:"priceToBook"`
+			EnterpriseToEbitda *float64 `json:"enterpriseToEbitda"`
+			DividendYield      *float64 `json:"dividendYield"`
+			TrailingPE         *float64 `json:"trailingPE"`
+		} `json:"defaultKeyStatistics"`
+		FinancialData struct {
+			ReturnOnEquity *float64 `json:"returnOnEquity"`
+			ProfitMargins  *float64 `json:"profitMargins"`
+			Ebitda         *float64 `json:"ebitda"`
+			TotalDebt      *float64 `json:"totalDebt"`
+			TotalCash      *float64 `json:"totalCash"`
+		} `json:"financialData"`
+		IncomeStatementHistoryQuarterly []brapiStatement `json:"incomeStatementHistoryQuarterly"`
+		BalanceSheetHistoryQuarterly    []brapiStatement `json:"balanceSheetHistoryQuarterly"`
+	} `json:"results"`
+}
+
+// brapiStatement é um demonstrativo trimestral (DRE ou balanço patrimonial), em reais
+type brapiStatement struct {
+	EndDate                string   `json:"endDate"`
+	TotalRevenue           *float64 `json:"totalRevenue"`
+	NetIncome              *float64 `json:"netIncome"`
+	TotalStockholderEquity *float64 `json:"totalStockholderEquity"`
+}
+
+// fetchFundamentals busca na brapi.dev os demonstrativos trimestrais e os indicadores atuais da empresa.
+// Retorna um registro por trimestre divulgado e, por último, o do trimestre corrente com os indicadores atuais
+func fetchFundamentals(symbol string, now time.Time) ([]models.Fundamento, error) {
+	url := fmt.Sprintf("https://brapi.dev/api/quote/%s?modules=defaultKeyStatistics,financialData,incomeStatementHistoryQuarterly,balanceSheetHistoryQuarterly&fundamental=true&token=%s", symbol, os.Getenv("BRAPI_TOKEN"))
+
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar indicadores na brapi.dev: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("brapi.dev retornou status %d", resp.StatusCode)
+	}
+
+	var brapiResp brapiFundamentalsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&brapiResp); err != nil {
+		return nil, fmt.Errorf("erro ao decodificar indicadores: %w", err)
+	}
+	if len(brapiResp.Results) == 0 {
+		return nil, fmt.Errorf("ativo %s não encontrado", symbol)
+	}
+
+	result := brapiResp.Results[0]
+	stats := result.DefaultKeyStatistics
+	financial := result.FinancialData
+
+	// Razões vêm como fração (0.25), guardamos em percentual (25); o dividend yield já vem em percentual
+	percent := func(v *float64) *float64 {
+		if v == nil {
+			return nil
+		}
+		p := *v * 100
+		return &p
+	}
+
+	fundamento := models.Fundamento{
+		Symbol:        symbol,
+		Ano:           now.Year(),
+		Trimestre:     quarterOf(now),
+		PL:            result.PriceEarnings,
+		PVP:           stats.PriceToBook,
+		ROE:           percent(financial.ReturnOnEquity),
+		MargemLiquida: percent(financial.ProfitMargins),
+		DividendYield: stats.DividendYield,
+		EvEbitda:      stats.EnterpriseToEbitda,
+	}
+	if fundamento.PL == nil {
+		fundamento.PL = stats.TrailingPE
+	}
+
+	if financial.Ebitda != nil && *financial.Ebitda != 0 && financial.TotalDebt != nil {
+		netDebt := *financial.TotalDebt
+		if financial.TotalCash != nil {
+			netDebt -= *financial.TotalCash
+		}
+		ratio := netDebt / *financial.Ebitda
+		fundamento.DividaLiquidaEbitda = &ratio
+	}
+
+	fundamentos := quarterlyFundamentals(symbol, result.IncomeStatementHistoryQuarterly, result.BalanceSheetHistoryQuarterly)
+	return append(fundamentos, fundamento), nil
+}
+
+func quarterOf(t time.Time) int {
+	return (int(t.Month())-1)/3 + 1
+}
+
+type fiscalQuarter struct {
+	ano, trimestre int
+}
+
+func (q fiscalQuarter) previous() fiscalQuarter {
+	if q.trimestre == 1 {
+		return fiscalQuarter{q.ano - 1, 4}
+	}
+	return fiscalQuarter{q.ano, q.trimestre - 1}
+}
+
+// quarterlyFundamentals monta um registro por trimestre divulgado a partir da DRE e do balanço:
+// receita, lucro e patrimônio do trimestre, margem líquida do trimestre e ROE dos últimos 12 meses
+// (só quando os quatro trimestres estão disponíveis)
+func quarterlyFundamentals(symbol string, income, balance []brapiStatement) []models.Fundamento {
+	parse := func(endDate string) (fiscalQuarter, bool) {
+		if len(endDate) < 10 {
+			return fiscalQuarter{}, false
+		}
+		t, err := time.Parse("2006-01-02", endDate[:10])
+		if err != nil {
+			return fiscalQuarter{}, false
+		}
+		return fiscalQuarter{t.Year(), quarterOf(t)}, true
+	}
+
+	equity := make(map[fiscalQuarter]*float64)
+	for _, b := range balance {
+		if q, ok := parse(b.EndDate); ok {
+			equity[q] = b.TotalStockholderEquity
+		}
+	}
+	netIncome := make(map[fiscalQuarter]*float64)
+	for _, i := range income {
+		if q, ok := parse(i.EndDate); ok {
+			netIncome[q] = i.NetIncome
+		}
+	}
+
+	fundamentos := []models.Fundamento{}
+	for _, i := range income {
+		q, ok := parse(i.EndDate)
+		if !ok {
+			continue
+		}
+		f := models.Fundamento{
+			Symbol:            symbol,
+			Ano:               q.ano,
+			Trimestre:         q.trimestre,
+			ReceitaLiquida:    i.TotalRevenue,
+			LucroLiquido:      i.NetIncome,
+			PatrimonioLiquido: equity[q],
+		}
+		if i.NetIncome != nil && i.TotalRevenue != nil && *i.TotalRevenue != 0 {
+			margem := *i.NetIncome / *i.TotalRevenue * 100
+			f.MargemLiquida = &margem
+		}
+
+		// ROE dos últimos 12 meses: lucro dos quatro trimestres sobre o patrimônio ao fim do trimestre
+		var lucro12m float64
+		complete := true
+		for j, prev := 0, q; j < 4; j, prev = j+1, prev.previous() {
+			if netIncome[prev] == nil {
+				complete = false
+				break
+			}
+			lucro12m += *netIncome[prev]
+		}
+		if pl := equity[q]; complete && pl != nil && *pl > 0 {
+			roe := lucro12m / *pl * 100
+			f.ROE = &roe
+		}
+
+		fundamentos = append(fundamentos, f)
+	}
+
+	sort.Slice(fundamentos, func(a, b int) bool {
+		if fundamentos[a].Ano != fundamentos[b].Ano {
+			return fundamentos[a].Ano < fundamentos[b].Ano
+		}
+		return fundamentos[a].Trimestre < fundamentos[b].Trimestre
+	})
+	return fundamentos
+}
+
+// saveFundamentals grava (ou atualiza) os registros de cada trimestre. Campos ausentes não apagam os
+// já gravados, para que os demonstrativos de um trimestre não descartem os múltiplos lidos nele
+func saveFundamentals(fundamentos []models.Fundamento) error {
+	query := `INSERT INTO fundamentos (symbol, ano, trimestre, pl, pvp, roe, margem_liquida, divida_liquida_ebitda, dividend_yield, ev_ebitda, receita_liquida, lucro_liquido, patrimonio_liquido)
+		VALUES (:symbol, :ano, :trimestre, :pl, :pvp, :roe, :margem_liquida, :divida_liquida_ebitda, :dividend_yield, :ev_ebitda, :receita_liquida, :lucro_liquido, :patrimonio_liquido)
+		ON CONFLICT (symbol, ano, trimestre) DO UPDATE SET
+			pl = COALESCE(EXCLUDED.pl, fundamentos.pl),
+			pvp = COALESCE(EXCLUDED.pvp, fundamentos.pvp),
+			roe = COALESCE(EXCLUDED.roe, fundamentos.roe),
+			margem_liquida = COALESCE(EXCLUDED.margem_liquida, fundamentos.margem_liquida),
+			divida_liquida_ebitda = COALESCE(EXCLUDED.divida_liquida_ebitda, fundamentos.divida_liquida_ebitda),
+			dividend_yield = COALESCE(EXCLUDED.dividend_yield, fundamentos.dividend_yield),
+			ev_ebitda = COALESCE(EXCLUDED.ev_ebitda, fundamentos.ev_ebitda),
+			receita_liquida = COALESCE(EXCLUDED.receita_liquida, fundamentos.receita_liquida),
+			lucro_liquido = COALESCE(EXCLUDED.lucro_liquido, fundamentos.lucro_liquido),
+			patrimonio_liquido = COALESCE(EXCLUDED.patrimonio_liquido, fundamentos.patrimonio_liquido),
+			updated_at = CURRENT_TIMESTAMP`
+
+	tx, err := config.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, f := range fundamentos {
+		if _, err := tx.NamedExec(query, f); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+const (
+	// Intervalo mínimo entre atualizações dos indicadores de um ativo
+	fundamentalsRefreshInterval = 24 * time.Hour
+	// Após uma falha (ex: ativo que a brapi.dev não conhece), o ativo só é consultado de novo depois deste intervalo
+	fundamentalsFailureTTL = 30 * time.Minute
+)
+
+// refreshFundamentals atualiza os indicadores do ativo no máximo uma vez por dia. Falhas da brapi.dev
+// também ficam em cache por um tempo, para que ativos desconhecidos não sejam consultados a cada requisição
+func refreshFundamentals(symbol string) error {
+	cacheKey := "fundamentos:" + symbol
+	if _, found := cache.GlobalCache.Get(cacheKey); found {
+		return nil
+	}
+
+	fundamentos, err := fetchFundamentals(symbol, time.Now())
+	if err != nil {
+		cache.GlobalCache.Set(cacheKey, false, fundamentalsFailureTTL)
+		return err
+	}
+	if err := saveFundamentals(fundamentos); err != nil {
+		return fmt.Errorf("erro ao gravar indicadores: %w", err)
+	}
+	cache.GlobalCache.Set(cacheKey, true, fundamentalsRefreshInterval)
+	return nil
+}
+
+// Pausa entre ativos na atualização em segundo plano, para não esgotar a cota da brapi.dev
+const fundamentalsJobPause = 2 * time.Second
+
+// StartFundamentalsRefresher atualiza periodicamente os indicadores de todas as ações de cotacoes,
+// para que o screener tenha indicadores mesmo das ações que ninguém consultou em /fundamentals
+func StartFundamentalsRefresher(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if err := refreshAllFundamentals(); err != nil {
+				log.Println("⚠️  Erro ao atualizar indicadores das ações:", err)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// refreshAllFundamentals atualiza as ações sem indicadores gravados nas últimas 24 horas
+func refreshAllFundamentals() error {
+	var symbols []string
+	err := config.DB.Select(&symbols, `SELECT c.symbol FROM cotacoes c
+		WHERE NOT EXISTS (
+			SELECT 1 FROM fundamentos f
+			WHERE f.symbol = c.symbol AND f.updated_at > NOW() - INTERVAL '24 hours'
+		)
+		ORDER BY c.symbol`)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, symbol := range symbols {
+		if !validTicker(symbol) {
+			continue
+		}
+		if err := refreshFundamentals(symbol); err != nil {
+			log.Println("⚠️  Erro ao atualizar indicadores de", symbol+":", err)
+			failed++
+		}
+		time.Sleep(fundamentalsJobPause)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d de %d ações sem indicadores atualizados", failed, len(symbols))
+	}
+	return nil
+}
+
+// GetFundamentals godoc
+// @Summary Indicadores fundamentalistas
+// @Description Obtém o histórico trimestral da empresa (receita, lucro, patrimônio, margem líquida do trimestre e ROE de 12 meses, dos demonstrativos trimestrais) e, no trimestre corrente, P/L, P/VP, ROE, margem líquida, dívida líquida/EBITDA, dividend yield e EV/EBITDA atuais. ROE, margem líquida e dividend yield são percentuais (6.5 = 6,5%)
+// @Tags stocks
+// @Accept  json
+// @Produce  json
+// @Param symbol path string true "Código de negociação (ex: PETR4)"
+// @Success 200 {object} FundamentalsResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /stocks/{symbol}/fundamentals [get]
+func GetFundamentals(c *gin.Context) {
+	symbol := strings.ToUpper(strings.TrimSpace(c.Param("symbol")))
+	if !validTicker(symbol) {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "symbol inválido"})
+		return
+	}
+
+	if err := refreshFundamentals(symbol); err != nil {
+		log.Println("⚠️  Erro ao atualizar indicadores de", symbol+":", err)
+	}
+
+	var historico []models.Fundamento
+	err := config.DB.Select(&historico, "SELECT * FROM fundamentos WHERE symbol = $1 ORDER BY ano DESC, trimestre DESC", symbol)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar indicadores"})
+		return
+	}
+
+	if len(historico) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"erro": "Indicadores não encontrados para " + symbol})
+		return
+	}
+
+	c.JSON(http.StatusOK, FundamentalsResponse{
+		Symbol:    symbol,
+		Historico: historico,
+	})
+}
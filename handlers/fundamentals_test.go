@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"math"
+	"testing"
+)
+
+func TestQuarterlyFundamentals(t *testing.T) {
+	f := func(v float64) *float64 { return &v }
+
+	income := []brapiStatement{
+		{EndDate: "2024-03-31", TotalRevenue: f(1000), NetIncome: f(100)},
+		{EndDate: "2023-12-31", TotalRevenue: f(900), NetIncome: f(90)},
+		{EndDate: "2023-09-30", TotalRevenue: f(800), NetIncome: f(80)},
+		{EndDate: "2023-06-30T00:00:00.000Z", TotalRevenue: f(700), NetIncome: f(70)},
+		{EndDate: "2023-03-31", TotalRevenue: f(0), NetIncome: f(60)},
+		{EndDate: "data inválida", TotalRevenue: f(1), NetIncome: f(1)},
+	}
+	balance := []brapiStatement{
+		{EndDate: "2024-03-31", TotalStockholderEquity: f(1360)},
+		{EndDate: "2023-12-31", TotalStockholderEquity: f(1200)},
+		{EndDate: "2023-06-30", TotalStockholderEquity: f(-10)},
+	}
+
+	tests := []struct {
+		ano, trimestre int
+		receita        *float64
+		patrimonio     *float64
+		margem         *float64
+		roe            *float64
+	}{
+		{ano: 2023, trimestre: 1, receita: f(0)},                                      // receita zero: sem margem
+		{ano: 2023, trimestre: 2, receita: f(700), patrimonio: f(-10), margem: f(10)}, // sem quatro trimestres: sem ROE
+		{ano: 2023, trimestre: 3, receita: f(800), margem: f(10)},                     // sem balanço: sem patrimônio nem ROE
+		{ano: 2023, trimestre: 4, receita: f(900), patrimonio: f(1200), margem: f(10), roe: f(25)},
+		{ano: 2024, trimestre: 1, receita: f(1000), patrimonio: f(1360), margem: f(10), roe: f(25)},
+	}
+
+	got := quarterlyFundamentals("PETR4", income, balance)
+	if len(got) != len(tests) {
+		t.Fatalf("quarterlyFundamentals retornou %d trimestres, esperado %d: %+v", len(got), len(tests), got)
+	}
+
+	equal := func(a, b *float64) bool {
+		if a == nil || b == nil {
+			return a == b
+		}
+		return math.Abs(*a-*b) < 1e-9
+	}
+	show := func(v *float64) interface{} {
+		if v == nil {
+			return nil
+		}
+		return *v
+	}
+
+	for i, tt := range tests {
+		g := got[i]
+		if g.Symbol != "PETR4" || g.Ano != tt.ano || g.Trimestre != tt.trimestre {
+			t.Errorf("item %d = %s %d/T%d, esperado PETR4 %d/T%d", i, g.Symbol, g.Ano, g.Trimestre, tt.ano, tt.trimestre)
+			continue
+		}
+		if !equal(g.ReceitaLiquida, tt.receita) || !equal(g.PatrimonioLiquido, tt.patrimonio) || !equal(g.MargemLiquida, tt.margem) || !equal(g.ROE, tt.roe) {
+			t.Errorf("%d/T%d: receita %v, patrimônio %v, margem %v, ROE %v; esperado %v, %v, %v, %v", tt.ano, tt.trimestre,
+				show(g.ReceitaLiquida), show(g.PatrimonioLiquido), show(g.MargemLiquida), show(g.ROE),
+				show(tt.receita), show(tt.patrimonio), show(tt.margem), show(tt.roe))
+		}
+	}
+}
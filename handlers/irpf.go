@@ -0,0 +1,391 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"go-br-finance-api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Limite mensal de vendas de ações para isenção do ganho de capital (swing trade)
+const limiteIsencaoAcoes = 20000.0
+
+type codigoBem struct {
+	grupo     string
+	codigo    string
+	descricao string
+}
+
+// Grupos e códigos da ficha Bens e Direitos por tipo de ativo
+var codigosBens = map[string]codigoBem{
+	models.TipoAcao:      {"03", "01", "Ações (inclusive as listadas em bolsa)"},
+	models.TipoFII:       {"07", "03", "Fundos de Investimento Imobiliário (FII)"},
+	models.TipoETF:       {"07", "09", "Fundos de índice (ETF)"},
+	models.TipoBDR:       {"04", "04", "Ativos negociados em bolsa no Brasil (BDR)"},
+	models.TipoTesouro:   {"04", "01", "Títulos públicos e privados sujeitos à tributação (Tesouro Direto)"},
+	models.TipoRendaFixa: {"04", "01", "Títulos públicos e privados sujeitos à tributação (CDB, RDB e outros)"},
+}
+
+type BemDireito struct {
+	Grupo            string  `json:"grupo"`
+	Codigo           string  `json:"codigo"`
+	Descricao        string  `json:"descricao"`
+	TipoAtivo        string  `json:"tipo_ativo"`
+	Symbol           string  `json:"symbol"`
+	Discriminacao    string  `json:"discriminacao"`
+	Quantidade       float64 `json:"quantidade"`
+	SituacaoAnterior float64 `json:"situacao_anterior"` // custo em 31/12 do ano anterior
+	SituacaoAtual    float64 `json:"situacao_atual"`    // custo em 31/12 do ano
+}
+
+type RendimentoIRPF struct {
+	Ficha     string  `json:"ficha"` // isentos ou exclusiva
+	Codigo    string  `json:"codigo"`
+	Descricao string  `json:"descricao"`
+	Symbol    string  `json:"symbol"`
+	Valor     float64 `json:"valor"`
+}
+
+type ApuracaoMensal struct {
+	Mes                string  `json:"mes"` // YYYY-MM
+	VendasAcoes        float64 `json:"vendas_acoes"`
+	IsentoAcoes        bool    `json:"isento_acoes"`
+	ResultadoAcoes     float64 `json:"resultado_acoes"`
+	ResultadoETFBDR    float64 `json:"resultado_etf_bdr"`
+	ResultadoFII       float64 `json:"resultado_fii"`
+	BaseComum          float64 `json:"base_comum"`
+	BaseFII            float64 `json:"base_fii"`
+	ImpostoComum       float64 `json:"imposto_comum"` // 15%
+	ImpostoFII         float64 `json:"imposto_fii"`   // 20%
+	ImpostoDevido      float64 `json:"imposto_devido"`
+	PrejuizoComumSaldo float64 `json:"prejuizo_comum_saldo"`
+	PrejuizoFIISaldo   float64 `json:"prejuizo_fii_saldo"`
+}
+
+type IRPFReport struct {
+	Ano                   int              `json:"ano"`
+	BensDireitos          []BemDireito     `json:"bens_direitos"`
+	RendimentosIsentos    []RendimentoIRPF `json:"rendimentos_isentos"`
+	RendimentosExclusivos []RendimentoIRPF `json:"rendimentos_exclusivos"`
+	TotalIsentos          float64          `json:"total_isentos"`
+	TotalExclusivos       float64          `json:"total_exclusivos"`
+	ApuracaoMensal        []ApuracaoMensal `json:"apuracao_mensal"`
+	Observacoes           []string         `json:"observacoes"`
+}
+
+// positionsAt retorna as posições ao final do dia informado
+func positionsAt(transacoes []models.Transacao, date string) map[string]*posicaoAcumulada {
+	var ate []models.Transacao
+	for _, t := range transacoes {
+		if t.Data <= date {
+			ate = append(ate, t)
+		}
+	}
+	posicoes, _ := replayTransactions(ate)
+	return posicoes
+}
+
+// buildBensDireitos monta a ficha com a situação em 31/12 do ano e do ano anterior
+func buildBensDireitos(transacoes []models.Transacao, ano int) []BemDireito {
+	atual := positionsAt(transacoes, fmt.Sprintf("%d-12-31", ano))
+	anterior := positionsAt(transacoes, fmt.Sprintf("%d-12-31", ano-1))
+
+	keys := make(map[string]bool)
+	for k := range atual {
+		keys[k] = true
+	}
+	for k := range anterior {
+		keys[k] = true
+	}
+
+	bens := []BemDireito{}
+	for key := range keys {
+		p := atual[key]
+		if p == nil {
+			p = anterior[key]
+		}
+		codigo := codigosBens[p.tipoAtivo]
+
+		bem := BemDireito{
+			Grupo:     codigo.grupo,
+			Codigo:    codigo.codigo,
+			Descricao: codigo.descricao,
+			TipoAtivo: p.tipoAtivo,
+			Symbol:    p.symbol,
+		}
+		if a := anterior[key]; a != nil {
+			bem.SituacaoAnterior = a.custo
+		}
+		if a := atual[key]; a != nil {
+			bem.Quantidade = a.quantidade
+			bem.SituacaoAtual = a.custo
+			bem.Discriminacao = fmt.Sprintf("%s - %s unidades ao preço médio de R$ %.2f", a.symbol,
+				strconv.FormatFloat(a.quantidade, 'f', -1, 64), a.custo/a.quantidade)
+		} else {
+			bem.Discriminacao = fmt.Sprintf("%s - posição encerrada em %d", p.symbol, ano)
+		}
+		bens = append(bens, bem)
+	}
+
+	sort.Slice(bens, func(i, j int) bool {
+		if bens[i].Grupo+bens[i].Codigo != bens[j].Grupo+bens[j].Codigo {
+			return bens[i].Grupo+bens[i].Codigo < bens[j].Grupo+bens[j].Codigo
+		}
+		return bens[i].Symbol < bens[j].Symbol
+	})
+	return bens
+}
+
+// buildRendimentos agrupa os proventos do ano por ficha, código e ativo
+func buildRendimentos(proventos []models.Provento, ano int) (isentos, exclusivos []RendimentoIRPF) {
+	totals := make(map[string]*RendimentoIRPF)
+	var order []string
+	prefix := strconv.Itoa(ano) + "-"
+
+	for _, p := range proventos {
+		if len(p.Data) < 5 || p.Data[:5] != prefix {
+			continue
+		}
+
+		var r RendimentoIRPF
+		switch p.Tipo {
+		case models.ProventoDividendo:
+			r = RendimentoIRPF{Ficha: "isentos", Codigo: "09", Descricao: "Lucros e dividendos recebidos"}
+		case models.ProventoRendimento:
+			r = RendimentoIRPF{Ficha: "isentos", Codigo: "99", Descricao: "Outros (rendimentos de FII)"}
+		case models.ProventoJCP:
+			r = RendimentoIRPF{Ficha: "exclusiva", Codigo: "10", Descricao: "Juros sobre capital próprio"}
+		default:
+			continue
+		}
+		r.Symbol = p.Symbol
+
+		key := r.Ficha + r.Codigo + r.Symbol
+		if totals[key] == nil {
+			totals[key] = &r
+			order = append(order, key)
+		}
+		totals[key].Valor += p.Valor
+	}
+
+	isentos, exclusivos = []RendimentoIRPF{}, []RendimentoIRPF{}
+	for _, key := range order {
+		if totals[key].Ficha == "isentos" {
+			isentos = append(isentos, *totals[key])
+		} else {
+			exclusivos = append(exclusivos, *totals[key])
+		}
+	}
+	return isentos, exclusivos
+}
+
+// buildApuracaoMensal apura o ganho de capital mensal em operações comuns (swing trade).
+// Ações, ETFs e BDRs compartilham o prejuízo a compensar (15%); FIIs têm apuração própria (20%).
+func buildApuracaoMensal(transacoes []models.Transacao, ano int) []ApuracaoMensal {
+	_, ganhos := replayTransactions(transacoes)
+
+	meses := make(map[string]*ApuracaoMensal)
+	for _, g := range ganhos {
+		mes := g.Data[:7]
+		if mes > fmt.Sprintf("%d-12", ano) {
+			continue
+		}
+		if meses[mes] == nil {
+			meses[mes] = &ApuracaoMensal{Mes: mes}
+		}
+		m := meses[mes]
+
+		switch g.TipoAtivo {
+		case models.TipoAcao:
+			m.VendasAcoes += g.ValorVenda
+			m.ResultadoAcoes += g.Resultado
+		case models.TipoETF, models.TipoBDR:
+			m.ResultadoETFBDR += g.Resultado
+		case models.TipoFII:
+			m.ResultadoFII += g.Resultado
+		}
+	}
+
+	ordem := make([]string, 0, len(meses))
+	for mes := range meses {
+		ordem = append(ordem, mes)
+	}
+	sort.Strings(ordem)
+
+	// Prejuízos de anos anteriores também são compensados, por isso a apuração começa na primeira venda
+	var prejuizoComum, prejuizoFII float64
+	apuracao := []ApuracaoMensal{}
+	for _, mes := range ordem {
+		m := meses[mes]
+
+		m.IsentoAcoes = m.VendasAcoes <= limiteIsencaoAcoes
+		resultadoComum := m.ResultadoETFBDR
+		if !m.IsentoAcoes || m.ResultadoAcoes < 0 {
+			resultadoComum += m.ResultadoAcoes
+		}
+
+		m.BaseComum, prejuizoComum = compensarPrejuizo(resultadoComum, prejuizoComum)
+		m.BaseFII, prejuizoFII = compensarPrejuizo(m.ResultadoFII, prejuizoFII)
+		m.ImpostoComum = m.BaseComum * 0.15
+		m.ImpostoFII = m.BaseFII * 0.20
+		m.ImpostoDevido = m.ImpostoComum + m.ImpostoFII
+		m.PrejuizoComumSaldo = prejuizoComum
+		m.PrejuizoFIISaldo = prejuizoFII
+
+		if mes[:4] == strconv.Itoa(ano) {
+			apuracao = append(apuracao, *m)
+		}
+	}
+
+	return apuracao
+}
+
+// compensarPrejuizo abate o prejuízo acumulado do resultado do mês e retorna a base de cálculo e o novo saldo
+func compensarPrejuizo(resultado, prejuizo float64) (float64, float64) {
+	if resultado < 0 {
+		return 0, prejuizo - resultado
+	}
+	if resultado <= prejuizo {
+		return 0, prejuizo - resultado
+	}
+	return resultado - prejuizo, 0
+}
+
+const irpfHTMLTemplate = `<!DOCTYPE html>
+<html lang="pt-BR">
+<head>
+<meta charset="utf-8">
+<title>Relatório IRPF {{.Ano}}</title>
+<style>
+body { font-family: Arial, sans-serif; font-size: 12px; margin: 24px; }
+h1 { font-size: 18px; } h2 { font-size: 14px; margin-top: 24px; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #999; padding: 4px 6px; text-align: left; }
+td.num { text-align: right; }
+@media print { body { margin: 0; } }
+</style>
+</head>
+<body>
+<h1>Relatório auxiliar para a declaração IRPF - ano-calendário {{.Ano}}</h1>
+
+<h2>Bens e Direitos</h2>
+<table>
+<tr><th>Grupo</th><th>Código</th><th>Discriminação</th><th>Situação em 31/12/{{.AnoAnterior}}</th><th>Situação em 31/12/{{.Ano}}</th></tr>
+{{range .BensDireitos}}<tr><td>{{.Grupo}}</td><td>{{.Codigo}}</td><td>{{.Discriminacao}}</td><td class="num">{{brl .SituacaoAnterior}}</td><td class="num">{{brl .SituacaoAtual}}</td></tr>
+{{end}}</table>
+
+<h2>Rendimentos Isentos e Não Tributáveis</h2>
+<table>
+<tr><th>Código</th><th>Descrição</th><th>Ativo</th><th>Valor</th></tr>
+{{range .RendimentosIsentos}}<tr><td>{{.Codigo}}</td><td>{{.Descricao}}</td><td>{{.Symbol}}</td><td class="num">{{brl .Valor}}</td></tr>
+{{end}}<tr><th colspan="3">Total</th><th class="num">{{brl .TotalIsentos}}</th></tr>
+</table>
+
+<h2>Rendimentos Sujeitos à Tributação Exclusiva/Definitiva</h2>
+<table>
+<tr><th>Código</th><th>Descrição</th><th>Ativo</th><th>Valor</th></tr>
+{{range .RendimentosExclusivos}}<tr><td>{{.Codigo}}</td><td>{{.Descricao}}</td><td>{{.Symbol}}</td><td class="num">{{brl .Valor}}</td></tr>
+{{end}}<tr><th colspan="3">Total</th><th class="num">{{brl .TotalExclusivos}}</th></tr>
+</table>
+
+<h2>Renda Variável - Apuração Mensal (operações comuns)</h2>
+<table>
+<tr><th>Mês</th><th>Vendas de ações</th><th>Resultado ações</th><th>Resultado ETF/BDR</th><th>Resultado FII</th><th>Base comum</th><th>Base FII</th><th>Imposto devido</th></tr>
+{{range .ApuracaoMensal}}<tr><td>{{.Mes}}</td><td class="num">{{brl .VendasAcoes}}{{if .IsentoAcoes}} (isento){{end}}</td><td class="num">{{brl .ResultadoAcoes}}</td><td class="num">{{brl .ResultadoETFBDR}}</td><td class="num">{{brl .ResultadoFII}}</td><td class="num">{{brl .BaseComum}}</td><td class="num">{{brl .BaseFII}}</td><td class="num">{{brl .ImpostoDevido}}</td></tr>
+{{end}}</table>
+
+<h2>Observações</h2>
+<ul>
+{{range .Observacoes}}<li>{{.}}</li>
+{{end}}</ul>
+</body>
+</html>`
+
+var irpfTemplate = template.Must(template.New("irpf").Funcs(template.FuncMap{
+	"brl": func(v float64) string { return fmt.Sprintf("R$ %.2f", v) },
+}).Parse(irpfHTMLTemplate))
+
+// GetIRPFReport godoc
+// @Summary Relatório para o IRPF
+// @Description Gera a ficha Bens e Direitos em 31/12, os rendimentos isentos e de tributação exclusiva (dividendos, JCP e rendimentos de FII) e a apuração mensal de ganhos em renda variável. Use format=html para uma versão imprimível (salvar como PDF pelo navegador)
+// @Tags portfolio
+// @Accept  json
+// @Produce  json
+// @Produce  html
+// @Param X-User-ID header string true "Identificador do usuário"
+// @Param year path int true "Ano-calendário"
+// @Param format query string false "json ou html" default(json)
+// @Success 200 {object} IRPFReport
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /portfolio/irpf/{year} [get]
+func GetIRPFReport(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	ano, err := strconv.Atoi(c.Param("year"))
+	if err != nil || ano < 2000 || ano > time.Now().Year() {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Ano inválido"})
+		return
+	}
+
+	transacoes, err := loadTransactions(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar transações"})
+		return
+	}
+	proventos, err := loadProventos(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar proventos"})
+		return
+	}
+
+	report := IRPFReport{
+		Ano:            ano,
+		BensDireitos:   buildBensDireitos(transacoes, ano),
+		ApuracaoMensal: buildApuracaoMensal(transacoes, ano),
+		Observacoes: []string{
+			"Valores de Bens e Direitos pelo custo de aquisição (preço médio, incluindo taxas de compra).",
+			"Apuração considera apenas operações comuns (swing trade); operações de day trade não são identificadas.",
+			"O IRRF de 0,005% retido nas vendas não está deduzido do imposto devido.",
+			"Renda fixa é listada no código 04.01; títulos isentos (LCI, LCA, CRI, CRA) devem ser ajustados para o código 04.02.",
+			"Confira grupos e códigos com o programa da Receita Federal do ano da declaração.",
+		},
+	}
+	report.RendimentosIsentos, report.RendimentosExclusivos = buildRendimentos(proventos, ano)
+	for _, r := range report.RendimentosIsentos {
+		report.TotalIsentos += r.Valor
+	}
+	for _, r := range report.RendimentosExclusivos {
+		report.TotalExclusivos += r.Valor
+	}
+
+	if c.DefaultQuery("format", "json") == "html" {
+		// Renderiza antes de responder, para um erro no template virar 500 e não uma página pela metade
+		var html bytes.Buffer
+		err := irpfTemplate.Execute(&html, struct {
+			IRPFReport
+			AnoAnterior int
+		}{report, ano - 1})
+		if err != nil {
+			log.Println("⚠️  Erro ao gerar relatório IRPF em HTML:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao gerar relatório"})
+			return
+		}
+		c.Data(http.StatusOK, "text/html; charset=utf-8", html.Bytes())
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"go-br-finance-api/cache"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	moversCacheKey = "stock_movers"
+	// Quantidade máxima de ativos mantida em cada lista de destaques
+	moversMaxLimit = 50
+)
+
+type MarketMovers struct {
+	Gainers    []Stock   `json:"gainers"`
+	Losers     []Stock   `json:"losers"`
+	MostTraded []Stock   `json:"most_traded"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// computeMovers ordena a lista de ações e retorna os n maiores altas, baixas e volumes
+func computeMovers(stocks []Stock, n int) MarketMovers {
+	top := func(less func(a, b Stock) bool) []Stock {
+		sorted := make([]Stock, len(stocks))
+		copy(sorted, stocks)
+		sort.SliceStable(sorted, func(i, j int) bool { return less(sorted[i], sorted[j]) })
+		if n < len(sorted) {
+			sorted = sorted[:n]
+		}
+		return sorted
+	}
+
+	return MarketMovers{
+		Gainers:    top(func(a, b Stock) bool { return a.Change > b.Change }),
+		Losers:     top(func(a, b Stock) bool { return a.Change < b.Change }),
+		MostTraded: top(func(a, b Stock) bool { return a.Volume > b.Volume }),
+		UpdatedAt:  time.Now(),
+	}
+}
+
+// refreshMovers recalcula os destaques a partir da lista de ações em cache
+func refreshMovers(ctx context.Context, ttl time.Duration) (MarketMovers, error) {
+	stocks, err := loadStocks(ctx)
+	if err != nil {
+		return MarketMovers{}, err
+	}
+
+	movers := computeMovers(stocks, moversMaxLimit)
+	cache.GlobalCache.Set(moversCacheKey, movers, ttl)
+	return movers, nil
+}
+
+// StartMoversRefresher atualiza os destaques do mercado em segundo plano
+func StartMoversRefresher(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := refreshMovers(context.Background(), 2*interval); err != nil {
+				log.Println("⚠️  Erro ao atualizar destaques do mercado:", err)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// GetMarketMovers godoc
+// @Summary Get market movers
+// @Description Retrieve the top gainers, losers and most traded Brazilian stocks from the cached stock list
+// @Tags stocks
+// @Accept  json
+// @Produce  json
+// @Param limit query int false "Number of stocks per list (max 50)" default(10)
+// @Success 200 {object} MarketMovers
+// @Failure 500 {object} map[string]string
+// @Router /stocks/movers [get]
+func GetMarketMovers(c *gin.Context) {
+	limit := 10
+	if l, err := strconv.Atoi(c.DefaultQuery("limit", "10")); err == nil && l > 0 {
+		limit = l
+	}
+	if limit > moversMaxLimit {
+		limit = moversMaxLimit
+	}
+
+	var movers MarketMovers
+	if cachedData, found := cache.GlobalCache.Get(moversCacheKey); found {
+		movers = cachedData.(MarketMovers)
+	} else {
+		// Refresher ainda não rodou ou falhou, calcular sob demanda
+		var err error
+		movers, err = refreshMovers(context.Background(), 5*time.Minute)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	truncate := func(stocks []Stock) []Stock {
+		if limit < len(stocks) {
+			return stocks[:limit]
+		}
+		return stocks
+	}
+
+	c.JSON(http.StatusOK, MarketMovers{
+		Gainers:    truncate(movers.Gainers),
+		Losers:     truncate(movers.Losers),
+		MostTraded: truncate(movers.MostTraded),
+		UpdatedAt:  movers.UpdatedAt,
+	})
+}
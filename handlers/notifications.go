@@ -0,0 +1,268 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go-br-finance-api/config"
+	"go-br-finance-api/models"
+	"go-br-finance-api/notify"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+var topicosNotificacao = map[string]bool{
+	models.TopicoAlertas:       true,
+	models.TopicoRecomendacoes: true,
+	models.TopicoResumoDiario:  true,
+}
+
+type TopicsRequest struct {
+	Topicos []string `json:"topicos"`
+}
+
+// Tempo máximo de cada envio; cada dispositivo tem o seu, para um broadcast longo não estourar o prazo
+const notificationSendTimeout = 30 * time.Second
+
+// sendToTokens envia a notificação a cada dispositivo e remove os tokens rejeitados
+func sendToTokens(tokens []string, n notify.Notification) {
+	for _, token := range tokens {
+		ctx, cancel := context.WithTimeout(context.Background(), notificationSendTimeout)
+		err := config.Notifier.Send(ctx, token, n)
+		cancel()
+		if errors.Is(err, notify.ErrInvalidToken) {
+			config.DB.Exec("DELETE FROM dispositivos WHERE token = $1", token)
+			continue
+		}
+		if err != nil {
+			log.Println("⚠️  Erro ao enviar notificação:", err)
+		}
+	}
+}
+
+// notifyUser envia a notificação aos dispositivos do usuário, se ele assina o tópico
+func notifyUser(userID, topico string, n notify.Notification) {
+	if config.Notifier == nil {
+		return
+	}
+
+	var tokens []string
+	query := `SELECT d.token FROM dispositivos d
+		JOIN notificacao_topicos t ON t.user_id = d.user_id
+		WHERE d.user_id = $1 AND t.topico = $2`
+	if err := config.DB.Select(&tokens, query, userID, topico); err != nil {
+		log.Println("⚠️  Erro ao buscar dispositivos:", err)
+		return
+	}
+	sendToTokens(tokens, n)
+}
+
+// notifyTopic envia a notificação a todos os dispositivos dos assinantes do tópico
+func notifyTopic(topico string, n notify.Notification) {
+	if config.Notifier == nil {
+		return
+	}
+
+	var tokens []string
+	query := `SELECT d.token FROM dispositivos d
+		JOIN notificacao_topicos t ON t.user_id = d.user_id
+		WHERE t.topico = $1`
+	if err := config.DB.Select(&tokens, query, topico); err != nil {
+		log.Println("⚠️  Erro ao buscar dispositivos:", err)
+		return
+	}
+	sendToTokens(tokens, n)
+}
+
+// RegisterDevice godoc
+// @Summary Registrar dispositivo
+// @Description Registra (ou transfere para o usuário) o token de push do app. Novos usuários passam a assinar todos os tópicos
+// @Tags notifications
+// @Accept  json
+// @Produce  json
+// @Param X-User-ID header string true "Identificador do usuário"
+// @Param request body models.Dispositivo true "Token do dispositivo"
+// @Success 201 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /notifications/devices [post]
+func RegisterDevice(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var dispositivo models.Dispositivo
+	if err := c.ShouldBindJSON(&dispositivo); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Dados inválidos", "detalhes": err.Error()})
+		return
+	}
+
+	dispositivo.Plataforma = strings.ToLower(strings.TrimSpace(dispositivo.Plataforma))
+	if dispositivo.Plataforma == "" {
+		dispositivo.Plataforma = "android"
+	}
+	if dispositivo.Plataforma != "android" && dispositivo.Plataforma != "ios" {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "plataforma deve ser android ou ios"})
+		return
+	}
+
+	// Usuário sem tópicos configurados começa assinando todos
+	var configurados int
+	if err := config.DB.Get(&configurados, "SELECT COUNT(*) FROM notificacao_topicos WHERE user_id = $1", userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao registrar dispositivo"})
+		return
+	}
+	var devices int
+	if err := config.DB.Get(&devices, "SELECT COUNT(*) FROM dispositivos WHERE user_id = $1", userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao registrar dispositivo"})
+		return
+	}
+
+	query := `INSERT INTO dispositivos (user_id, token, plataforma) VALUES ($1, $2, $3)
+		ON CONFLICT (token) DO UPDATE SET user_id = EXCLUDED.user_id, plataforma = EXCLUDED.plataforma, updated_at = CURRENT_TIMESTAMP`
+	if _, err := config.DB.Exec(query, userID, strings.TrimSpace(dispositivo.Token), dispositivo.Plataforma); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao registrar dispositivo"})
+		return
+	}
+
+	if configurados == 0 && devices == 0 {
+		var topicos []string
+		for topico := range topicosNotificacao {
+			topicos = append(topicos, topico)
+		}
+		config.DB.Exec(`INSERT INTO notificacao_topicos (user_id, topico) SELECT $1, unnest($2::text[])
+			ON CONFLICT DO NOTHING`, userID, pq.Array(topicos))
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"mensagem": "Dispositivo registrado com sucesso"})
+}
+
+// UnregisterDevice godoc
+// @Summary Remover dispositivo
+// @Description Remove o token de push do usuário (ex: ao sair do app)
+// @Tags notifications
+// @Accept  json
+// @Produce  json
+// @Param X-User-ID header string true "Identificador do usuário"
+// @Param token path string true "Token do dispositivo"
+// @Success 200 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /notifications/devices/{token} [delete]
+func UnregisterDevice(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	result, err := config.DB.Exec("DELETE FROM dispositivos WHERE token = $1 AND user_id = $2", c.Param("token"), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao remover dispositivo"})
+		return
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"erro": "Dispositivo não encontrado"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"mensagem": "Dispositivo removido com sucesso"})
+}
+
+// GetNotificationTopics godoc
+// @Summary Listar tópicos assinados
+// @Description Lista os tópicos de notificação assinados pelo usuário (alertas, recomendacoes, resumo_diario)
+// @Tags notifications
+// @Accept  json
+// @Produce  json
+// @Param X-User-ID header string true "Identificador do usuário"
+// @Success 200 {object} TopicsRequest
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /notifications/topics [get]
+func GetNotificationTopics(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	topicos := []string{}
+	if err := config.DB.Select(&topicos, "SELECT topico FROM notificacao_topicos WHERE user_id = $1 ORDER BY topico", userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar tópicos"})
+		return
+	}
+
+	c.JSON(http.StatusOK, TopicsRequest{Topicos: topicos})
+}
+
+// UpdateNotificationTopics godoc
+// @Summary Atualizar tópicos assinados
+// @Description Substitui a lista de tópicos assinados pelo usuário. Uma lista vazia desativa todas as notificações
+// @Tags notifications
+// @Accept  json
+// @Produce  json
+// @Param X-User-ID header string true "Identificador do usuário"
+// @Param request body TopicsRequest true "Tópicos assinados"
+// @Success 200 {object} TopicsRequest
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /notifications/topics [put]
+func UpdateNotificationTopics(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req TopicsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Dados inválidos", "detalhes": err.Error()})
+		return
+	}
+
+	topicos := []string{}
+	vistos := make(map[string]bool)
+	for _, topico := range req.Topicos {
+		topico = strings.ToLower(strings.TrimSpace(topico))
+		if !topicosNotificacao[topico] {
+			c.JSON(http.StatusBadRequest, gin.H{"erro": "Tópico desconhecido: " + topico + " (use alertas, recomendacoes ou resumo_diario)"})
+			return
+		}
+		if !vistos[topico] {
+			vistos[topico] = true
+			topicos = append(topicos, topico)
+		}
+	}
+
+	tx, err := config.DB.Beginx()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao atualizar tópicos"})
+		return
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM notificacao_topicos WHERE user_id = $1", userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao atualizar tópicos"})
+		return
+	}
+	if len(topicos) > 0 {
+		if _, err := tx.Exec("INSERT INTO notificacao_topicos (user_id, topico) SELECT $1, unnest($2::text[])", userID, pq.Array(topicos)); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao atualizar tópicos"})
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao atualizar tópicos"})
+		return
+	}
+
+	c.JSON(http.StatusOK, TopicsRequest{Topicos: topicos})
+}
@@ -0,0 +1,455 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"go-br-finance-api/cache"
+	"go-br-finance-api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	dateLayout      = "2006-01-02"
+	ibovespaSymbol  = "^BVSP"
+	maxPeriodoAnos  = 10
+	historyCacheTTL = 6 * time.Hour
+)
+
+type PerformancePoint struct {
+	Data          string  `json:"data"`
+	ValorCarteira float64 `json:"valor_carteira"`
+	Rentabilidade float64 `json:"rentabilidade"` // TWR acumulado (%)
+	CDI           float64 `json:"cdi"`           // CDI acumulado (%)
+	Ibovespa      float64 `json:"ibovespa"`      // Ibovespa acumulado (%)
+}
+
+type PerformanceResponse struct {
+	De           string             `json:"de"`
+	Ate          string             `json:"ate"`
+	Intervalo    string             `json:"intervalo"`
+	ValorInicial float64            `json:"valor_inicial"`
+	ValorFinal   float64            `json:"valor_final"`
+	AportesLiq   float64            `json:"aportes_liquidos"`
+	TWR          float64            `json:"twr"`  // retorno ponderado pelo tempo no período (%)
+	XIRR         *float64           `json:"xirr"` // retorno ponderado pelo capital, anualizado (%)
+	CDI          float64            `json:"cdi"`
+	Ibovespa     float64            `json:"ibovespa"`
+	Serie        []PerformancePoint `json:"serie"`
+}
+
+// priceHistory guarda os fechamentos diários de um ativo em ordem cronológica
+type priceHistory struct {
+	dates  []string
+	closes map[string]float64
+}
+
+// closeAt retorna o último fechamento conhecido até a data (inclusive)
+func (h *priceHistory) closeAt(date string) (float64, bool) {
+	if h == nil {
+		return 0, false
+	}
+	i := sort.SearchStrings(h.dates, date)
+	if i < len(h.dates) && h.dates[i] == date {
+		return h.closes[date], true
+	}
+	if i == 0 {
+		return 0, false
+	}
+	return h.closes[h.dates[i-1]], true
+}
+
+type brapiHistoryResponse struct {
+	Results []struct {
+		HistoricalDataPrice []struct {
+			Date  int64   `json:"date"`
+			Close float64 `json:"close"`
+		} `json:"historicalDataPrice"`
+	} `json:"results"`
+}
+
+type bcbSerieItem struct {
+	Data  string `json:"data"`
+	Valor string `json:"valor"`
+}
+
+// brapiRange escolhe o menor intervalo da brapi.dev que cobre a data inicial
+func brapiRange(from time.Time) string {
+	days := time.Since(from).Hours() / 24
+	ranges := []struct {
+		name string
+		days float64
+	}{
+		{"1mo", 30}, {"3mo", 90}, {"6mo", 180}, {"1y", 365}, {"2y", 730}, {"5y", 1825}, {"10y", 3650},
+	}
+	for _, r := range ranges {
+		if days < r.days {
+			return r.name
+		}
+	}
+	return "max"
+}
+
+// fetchPriceHistory busca os fechamentos diários de um ativo na brapi.dev
+func fetchPriceHistory(symbol, rng string) (*priceHistory, error) {
+	if !validTicker(symbol) {
+		return nil, fmt.Errorf("symbol inválido: %q", symbol)
+	}
+
+	cacheKey := "historico:" + symbol + ":" + rng
+	if cachedData, found := cache.GlobalCache.Get(cacheKey); found {
+		return cachedData.(*priceHistory), nil
+	}
+
+	url := fmt.Sprintf("https://brapi.dev/api/quote/%s?range=%s&interval=1d&token=%s", symbol, rng, os.Getenv("BRAPI_TOKEN"))
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("brapi.dev retornou status %d para %s", resp.StatusCode, symbol)
+	}
+
+	var brapiResp brapiHistoryResponse
+	if err := json.NewDecoder(resp.Body).Decode(&brapiResp); err != nil {
+		return nil, err
+	}
+	if len(brapiResp.Results) == 0 {
+		return nil, fmt.Errorf("histórico de %s não encontrado", symbol)
+	}
+
+	history := &priceHistory{closes: make(map[string]float64)}
+	for _, p := range brapiResp.Results[0].HistoricalDataPrice {
+		if p.Close <= 0 {
+			continue
+		}
+		date := time.Unix(p.Date, 0).In(saoPauloLocation()).Format(dateLayout)
+		if _, exists := history.closes[date]; !exists {
+			history.dates = append(history.dates, date)
+		}
+		history.closes[date] = p.Close
+	}
+	sort.Strings(history.dates)
+
+	cache.GlobalCache.Set(cacheKey, history, historyCacheTTL)
+	return history, nil
+}
+
+// fetchCDISeries busca a taxa diária do CDI (% ao dia) no SGS do Banco Central (série 12)
+func fetchCDISeries(from, to time.Time) (map[string]float64, error) {
+	cacheKey := "cdi:" + from.Format(dateLayout) + ":" + to.Format(dateLayout)
+	if cachedData, found := cache.GlobalCache.Get(cacheKey); found {
+		return cachedData.(map[string]float64), nil
+	}
+
+	url := fmt.Sprintf("https://api.bcb.gov.br/dados/serie/bcdata.sgs.12/dados?formato=json&dataInicial=%s&dataFinal=%s",
+		from.Format("02/01/2006"), to.Format("02/01/2006"))
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("Banco Central retornou status %d", resp.StatusCode)
+	}
+
+	var items []bcbSerieItem
+	if err := json.NewDecoder(resp.Body).Decode(&items); err != nil {
+		return nil, err
+	}
+
+	rates := make(map[string]float64, len(items))
+	for _, item := range items {
+		date, err := time.Parse("02/01/2006", item.Data)
+		if err != nil {
+			continue
+		}
+		rate, err := strconv.ParseFloat(item.Valor, 64)
+		if err != nil {
+			continue
+		}
+		rates[date.Format(dateLayout)] = rate
+	}
+
+	cache.GlobalCache.Set(cacheKey, rates, historyCacheTTL)
+	return rates, nil
+}
+
+func saoPauloLocation() *time.Location {
+	loc, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		return time.FixedZone("BRT", -3*60*60)
+	}
+	return loc
+}
+
+// tradingDays usa os pregões do Ibovespa como calendário; sem histórico, usa dias úteis
+func tradingDays(ibov *priceHistory, from, to string) []string {
+	var days []string
+	if ibov != nil {
+		for _, d := range ibov.dates {
+			if d >= from && d <= to {
+				days = append(days, d)
+			}
+		}
+		if len(days) > 0 {
+			return days
+		}
+	}
+
+	start, _ := time.Parse(dateLayout, from)
+	end, _ := time.Parse(dateLayout, to)
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		if d.Weekday() != time.Saturday && d.Weekday() != time.Sunday {
+			days = append(days, d.Format(dateLayout))
+		}
+	}
+	return days
+}
+
+// portfolioValue avalia as posições na data usando o último fechamento conhecido
+// e o custo para ativos sem cotação (Tesouro, renda fixa ou sem histórico)
+func portfolioValue(posicoes map[string]*posicaoAcumulada, histories map[string]*priceHistory, date string) float64 {
+	var total float64
+	for _, p := range posicoes {
+		if p.quantidade <= quantidadeMinima {
+			continue
+		}
+		if price, ok := histories[p.symbol].closeAt(date); ok && tiposCotados[p.tipoAtivo] {
+			total += p.quantidade * price
+			continue
+		}
+		total += p.custo
+	}
+	return total
+}
+
+// cashFlow retorna o aporte líquido da transação (compras positivas, vendas negativas)
+func cashFlow(t models.Transacao) float64 {
+	if t.Operacao == models.OperacaoCompra {
+		return t.Quantidade*t.Preco + t.Taxas
+	}
+	return -(t.Quantidade*t.Preco - t.Taxas)
+}
+
+type datedFlow struct {
+	date   time.Time
+	amount float64
+}
+
+// xirr calcula a taxa interna de retorno anual para fluxos com datas irregulares.
+// Fluxos negativos são saídas do investidor; positivos, entradas.
+func xirr(flows []datedFlow) (float64, bool) {
+	if len(flows) < 2 {
+		return 0, false
+	}
+
+	t0 := flows[0].date
+	npv := func(rate float64) float64 {
+		var sum float64
+		for _, f := range flows {
+			years := f.date.Sub(t0).Hours() / 24 / 365
+			sum += f.amount / math.Pow(1+rate, years)
+		}
+		return sum
+	}
+
+	// Bisseção entre -99,99% e 10.000% ao ano
+	low, high := -0.9999, 100.0
+	fLow, fHigh := npv(low), npv(high)
+	if math.IsNaN(fLow) || math.IsNaN(fHigh) || fLow*fHigh > 0 {
+		return 0, false
+	}
+
+	for i := 0; i < 200; i++ {
+		mid := (low + high) / 2
+		fMid := npv(mid)
+		if math.Abs(fMid) < 1e-7 {
+			return mid, true
+		}
+		if fLow*fMid < 0 {
+			high = mid
+		} else {
+			low, fLow = mid, fMid
+		}
+	}
+	return (low + high) / 2, true
+}
+
+// GetPortfolioPerformance godoc
+// @Summary Rentabilidade da carteira
+// @Description Calcula o retorno ponderado pelo tempo (TWR) e pelo capital (XIRR) da carteira no período, comparado ao CDI e ao Ibovespa, com série diária ou mensal para gráficos
+// @Tags portfolio
+// @Accept  json
+// @Produce  json
+// @Param X-User-ID header string true "Identificador do usuário"
+// @Param from query string false "Data inicial (YYYY-MM-DD); padrão: primeira transação"
+// @Param to query string false "Data final (YYYY-MM-DD); padrão: hoje"
+// @Param interval query string false "daily ou monthly" default(daily)
+// @Success 200 {object} PerformanceResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /portfolio/performance [get]
+func GetPortfolioPerformance(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	interval := c.DefaultQuery("interval", "daily")
+	if interval != "daily" && interval != "monthly" {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "interval deve ser daily ou monthly"})
+		return
+	}
+
+	transacoes, err := loadTransactions(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar transações"})
+		return
+	}
+	if len(transacoes) == 0 {
+		c.JSON(http.StatusOK, PerformanceResponse{Intervalo: interval, Serie: []PerformancePoint{}})
+		return
+	}
+
+	today := time.Now().In(saoPauloLocation())
+	from := c.DefaultQuery("from", transacoes[0].Data)
+	to := c.DefaultQuery("to", today.Format(dateLayout))
+	fromDate, err1 := time.Parse(dateLayout, from)
+	toDate, err2 := time.Parse(dateLayout, to)
+	if err1 != nil || err2 != nil || fromDate.After(toDate) {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "from e to devem estar no formato YYYY-MM-DD, com from <= to"})
+		return
+	}
+	if fromDate.Before(today.AddDate(-maxPeriodoAnos, 0, 0)) {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": fmt.Sprintf("Período máximo de %d anos", maxPeriodoAnos)})
+		return
+	}
+
+	// Históricos de preço dos ativos cotados e do Ibovespa
+	rng := brapiRange(fromDate)
+	histories := make(map[string]*priceHistory)
+	for _, t := range transacoes {
+		if _, loaded := histories[t.Symbol]; loaded || !tiposCotados[t.TipoAtivo] {
+			continue
+		}
+		history, err := fetchPriceHistory(t.Symbol, rng)
+		if err != nil {
+			history = nil // avaliado pelo custo
+		}
+		histories[t.Symbol] = history
+	}
+
+	ibov, _ := fetchPriceHistory(ibovespaSymbol, rng)
+	cdi, err := fetchCDISeries(fromDate, toDate)
+	if err != nil {
+		cdi = map[string]float64{}
+	}
+
+	days := tradingDays(ibov, from, to)
+	if len(days) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Nenhum pregão no período informado"})
+		return
+	}
+
+	// Estado inicial: transações anteriores ao período
+	posicoes := make(map[string]*posicaoAcumulada)
+	next := 0
+	for next < len(transacoes) && transacoes[next].Data < days[0] {
+		applyTransacao(posicoes, transacoes[next])
+		next++
+	}
+
+	// Valores no fechamento anterior ao período, base para o primeiro dia
+	startDate := days[0]
+	startDay, _ := time.Parse(dateLayout, startDate)
+	dayBefore := startDay.AddDate(0, 0, -1).Format(dateLayout)
+	previousValue := portfolioValue(posicoes, histories, dayBefore)
+
+	ibovStart, hasIbov := ibov.closeAt(dayBefore)
+	if !hasIbov {
+		ibovStart, hasIbov = ibov.closeAt(startDate)
+	}
+
+	response := PerformanceResponse{
+		De:           startDate,
+		Ate:          days[len(days)-1],
+		Intervalo:    interval,
+		ValorInicial: previousValue,
+	}
+
+	var flows []datedFlow
+	if previousValue > 0 {
+		flows = append(flows, datedFlow{date: startDay, amount: -previousValue})
+	}
+
+	twrFactor, cdiFactor := 1.0, 1.0
+	var series []PerformancePoint
+	for _, day := range days {
+		var flow float64
+		for next < len(transacoes) && transacoes[next].Data <= day {
+			flow += cashFlow(transacoes[next])
+			applyTransacao(posicoes, transacoes[next])
+			next++
+		}
+
+		value := portfolioValue(posicoes, histories, day)
+		switch {
+		case previousValue > 0:
+			twrFactor *= (value - flow) / previousValue
+		case flow > 0:
+			twrFactor *= value / flow
+		}
+		if flow != 0 {
+			response.AportesLiq += flow
+			date, _ := time.Parse(dateLayout, day)
+			flows = append(flows, datedFlow{date: date, amount: -flow})
+		}
+		previousValue = value
+
+		cdiFactor *= 1 + cdi[day]/100
+
+		point := PerformancePoint{
+			Data:          day,
+			ValorCarteira: value,
+			Rentabilidade: (twrFactor - 1) * 100,
+			CDI:           (cdiFactor - 1) * 100,
+		}
+		if fechamento, ok := ibov.closeAt(day); ok && hasIbov && ibovStart > 0 {
+			point.Ibovespa = (fechamento/ibovStart - 1) * 100
+		}
+
+		if interval == "monthly" && len(series) > 0 && series[len(series)-1].Data[:7] == day[:7] {
+			series[len(series)-1] = point
+		} else {
+			series = append(series, point)
+		}
+	}
+
+	last := series[len(series)-1]
+	response.ValorFinal = last.ValorCarteira
+	response.TWR = last.Rentabilidade
+	response.CDI = last.CDI
+	response.Ibovespa = last.Ibovespa
+	response.Serie = series
+
+	endDate, _ := time.Parse(dateLayout, response.Ate)
+	flows = append(flows, datedFlow{date: endDate, amount: response.ValorFinal})
+	if rate, ok := xirr(flows); ok {
+		rate *= 100
+		response.XIRR = &rate
+	}
+
+	c.JSON(http.StatusOK, response)
+}
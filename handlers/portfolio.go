@@ -0,0 +1,398 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"go-br-finance-api/config"
+	"go-br-finance-api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Quantidade abaixo da qual a posição é considerada zerada
+const quantidadeMinima = 1e-9
+
+var tiposAtivo = map[string]bool{
+	models.TipoAcao:      true,
+	models.TipoFII:       true,
+	models.TipoETF:       true,
+	models.TipoBDR:       true,
+	models.TipoTesouro:   true,
+	models.TipoRendaFixa: true,
+}
+
+// Tipos cotados em bolsa, cujo valor atual vem da cotação
+var tiposCotados = map[string]bool{
+	models.TipoAcao: true,
+	models.TipoFII:  true,
+	models.TipoETF:  true,
+	models.TipoBDR:  true,
+}
+
+const transacaoColumns = `id, user_id, tipo_ativo, symbol, operacao, quantidade, preco, taxas, to_char(data, 'YYYY-MM-DD') AS data, created_at`
+
+type posicaoAcumulada struct {
+	tipoAtivo  string
+	symbol     string
+	quantidade float64
+	custo      float64
+}
+
+type ganhoRealizado struct {
+	TipoAtivo  string  `json:"tipo_ativo"`
+	Symbol     string  `json:"symbol"`
+	Data       string  `json:"data"`
+	Quantidade float64 `json:"quantidade"`
+	ValorVenda float64 `json:"valor_venda"`
+	Custo      float64 `json:"custo"`
+	Resultado  float64 `json:"resultado"`
+}
+
+func positionKey(tipoAtivo, symbol string) string {
+	return tipoAtivo + ":" + symbol
+}
+
+// loadTransactions retorna as transações do usuário em ordem cronológica
+func loadTransactions(userID string) ([]models.Transacao, error) {
+	var transacoes []models.Transacao
+	query := "SELECT " + transacaoColumns + " FROM portfolio_transacoes WHERE user_id = $1 ORDER BY data, id"
+	err := config.DB.Select(&transacoes, query, userID)
+	return transacoes, err
+}
+
+// replayTransactions aplica as transações em ordem cronológica pelo método do preço médio.
+// Taxas de compra entram no custo; taxas de venda reduzem o valor da venda.
+func replayTransactions(transacoes []models.Transacao) (map[string]*posicaoAcumulada, []ganhoRealizado) {
+	sorted := make([]models.Transacao, len(transacoes))
+	copy(sorted, transacoes)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Data < sorted[j].Data })
+
+	posicoes := make(map[string]*posicaoAcumulada)
+	var ganhos []ganhoRealizado
+
+	for _, t := range sorted {
+		if ganho := applyTransacao(posicoes, t); ganho != nil {
+			ganhos = append(ganhos, *ganho)
+		}
+	}
+
+	for key, p := range posicoes {
+		if p.quantidade <= quantidadeMinima {
+			delete(posicoes, key)
+		}
+	}
+
+	return posicoes, ganhos
+}
+
+// applyTransacao atualiza as posições com uma transação e retorna o ganho realizado nas vendas
+func applyTransacao(posicoes map[string]*posicaoAcumulada, t models.Transacao) *ganhoRealizado {
+	key := positionKey(t.TipoAtivo, t.Symbol)
+	p, exists := posicoes[key]
+	if !exists {
+		p = &posicaoAcumulada{tipoAtivo: t.TipoAtivo, symbol: t.Symbol}
+		posicoes[key] = p
+	}
+
+	if t.Operacao == models.OperacaoCompra {
+		p.quantidade += t.Quantidade
+		p.custo += t.Quantidade*t.Preco + t.Taxas
+		return nil
+	}
+
+	quantidade := math.Min(t.Quantidade, p.quantidade)
+	if quantidade <= quantidadeMinima {
+		return nil
+	}
+
+	custo := p.custo / p.quantidade * quantidade
+	valorVenda := quantidade*t.Preco - t.Taxas*quantidade/t.Quantidade
+
+	p.quantidade -= quantidade
+	p.custo -= custo
+	if p.quantidade <= quantidadeMinima {
+		p.quantidade, p.custo = 0, 0
+	}
+
+	return &ganhoRealizado{
+		TipoAtivo:  t.TipoAtivo,
+		Symbol:     t.Symbol,
+		Data:       t.Data,
+		Quantidade: quantidade,
+		ValorVenda: valorVenda,
+		Custo:      custo,
+		Resultado:  valorVenda - custo,
+	}
+}
+
+// currentPrices busca a cotação atual dos ativos negociados em bolsa,
+// usando a lista de ações em cache e consultando a brapi.dev para os demais
+func currentPrices(ctx context.Context, posicoes map[string]*posicaoAcumulada) map[string]float64 {
+	prices := make(map[string]float64)
+
+	if stocks, err := loadStocks(ctx); err == nil {
+		for _, stock := range stocks {
+			prices[stock.Symbol] = stock.Price
+		}
+	}
+
+	var missing []string
+	for _, p := range posicoes {
+		// Transações antigas podem ter símbolos que não são tickers; esses não vão para a URL da brapi.dev
+		if _, found := prices[p.symbol]; tiposCotados[p.tipoAtivo] && !found && validTicker(p.symbol) {
+			missing = append(missing, p.symbol)
+		}
+	}
+
+	if len(missing) > 0 {
+		quotes, err := fetchQuotes(missing)
+		if err != nil {
+			log.Println("⚠️  Erro ao buscar cotações da carteira:", err)
+		}
+		for _, q := range quotes {
+			prices[q.Symbol] = q.Price
+		}
+	}
+
+	return prices
+}
+
+// buildCarteira calcula posições, preço médio, valor atual e alocação do usuário
+func buildCarteira(ctx context.Context, userID string) (models.Carteira, error) {
+	transacoes, err := loadTransactions(userID)
+	if err != nil {
+		return models.Carteira{}, err
+	}
+
+	posicoesAcumuladas, ganhos := replayTransactions(transacoes)
+	prices := currentPrices(ctx, posicoesAcumuladas)
+
+	carteira := models.Carteira{Posicoes: []models.Posicao{}, Alocacao: []models.Alocacao{}}
+	porTipo := make(map[string]float64)
+
+	for _, p := range posicoesAcumuladas {
+		posicao := models.Posicao{
+			TipoAtivo:  p.tipoAtivo,
+			Symbol:     p.symbol,
+			Quantidade: p.quantidade,
+			PrecoMedio: p.custo / p.quantidade,
+			CustoTotal: p.custo,
+			PrecoAtual: p.custo / p.quantidade,
+			FontePreco: "custo",
+		}
+		if price, found := prices[p.symbol]; found && tiposCotados[p.tipoAtivo] && price > 0 {
+			posicao.PrecoAtual = price
+			posicao.FontePreco = "cotacao"
+		}
+
+		posicao.ValorAtual = posicao.PrecoAtual * posicao.Quantidade
+		posicao.ResultadoNaoRealizado = posicao.ValorAtual - posicao.CustoTotal
+		if posicao.CustoTotal > 0 {
+			posicao.ResultadoPercentual = posicao.ResultadoNaoRealizado / posicao.CustoTotal * 100
+		}
+
+		carteira.Posicoes = append(carteira.Posicoes, posicao)
+		carteira.CustoTotal += posicao.CustoTotal
+		carteira.ValorTotal += posicao.ValorAtual
+		porTipo[p.tipoAtivo] += posicao.ValorAtual
+	}
+	carteira.ResultadoNaoRealizado = carteira.ValorTotal - carteira.CustoTotal
+
+	for _, g := range ganhos {
+		carteira.ResultadoRealizado += g.Resultado
+	}
+
+	sort.Slice(carteira.Posicoes, func(i, j int) bool {
+		return carteira.Posicoes[i].ValorAtual > carteira.Posicoes[j].ValorAtual
+	})
+
+	for tipo, valor := range porTipo {
+		alocacao := models.Alocacao{TipoAtivo: tipo, Valor: valor}
+		if carteira.ValorTotal > 0 {
+			alocacao.Percentual = valor / carteira.ValorTotal * 100
+		}
+		carteira.Alocacao = append(carteira.Alocacao, alocacao)
+	}
+	sort.Slice(carteira.Alocacao, func(i, j int) bool {
+		return carteira.Alocacao[i].Valor > carteira.Alocacao[j].Valor
+	})
+
+	return carteira, nil
+}
+
+// validateTransacao normaliza e valida os campos de uma transação
+func validateTransacao(t *models.Transacao) error {
+	t.TipoAtivo = strings.ToLower(strings.TrimSpace(t.TipoAtivo))
+	t.Operacao = strings.ToLower(strings.TrimSpace(t.Operacao))
+	t.Symbol = strings.ToUpper(strings.TrimSpace(t.Symbol))
+
+	if !tiposAtivo[t.TipoAtivo] {
+		return fmt.Errorf("tipo_ativo deve ser acao, fii, etf, bdr, tesouro ou renda_fixa")
+	}
+	if t.Operacao != models.OperacaoCompra && t.Operacao != models.OperacaoVenda {
+		return fmt.Errorf("operacao deve ser compra ou venda")
+	}
+	if t.Symbol == "" || len(t.Symbol) > 100 {
+		return fmt.Errorf("symbol deve ter entre 1 e 100 caracteres")
+	}
+	if tiposCotados[t.TipoAtivo] && !validTicker(t.Symbol) {
+		return fmt.Errorf("symbol inválido para ativo negociado em bolsa: %q", t.Symbol)
+	}
+	if t.Quantidade <= 0 || t.Preco < 0 || t.Taxas < 0 {
+		return fmt.Errorf("quantidade deve ser positiva; preco e taxas não podem ser negativos")
+	}
+	if _, err := time.Parse("2006-01-02", t.Data); err != nil {
+		return fmt.Errorf("data deve estar no formato YYYY-MM-DD")
+	}
+	return nil
+}
+
+// GetPortfolio godoc
+// @Summary Consultar carteira
+// @Description Retorna as posições do usuário com preço médio, valor atual, resultado não realizado e alocação por tipo de ativo
+// @Tags portfolio
+// @Accept  json
+// @Produce  json
+// @Param X-User-ID header string true "Identificador do usuário"
+// @Success 200 {object} models.Carteira
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /portfolio [get]
+func GetPortfolio(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	carteira, err := buildCarteira(context.Background(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao calcular carteira"})
+		return
+	}
+
+	c.JSON(http.StatusOK, carteira)
+}
+
+// GetPortfolioTransactions godoc
+// @Summary Listar transações da carteira
+// @Description Lista as transações de compra e venda do usuário em ordem cronológica
+// @Tags portfolio
+// @Accept  json
+// @Produce  json
+// @Param X-User-ID header string true "Identificador do usuário"
+// @Success 200 {array} models.Transacao
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /portfolio/transactions [get]
+func GetPortfolioTransactions(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	transacoes, err := loadTransactions(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar transações"})
+		return
+	}
+
+	c.JSON(http.StatusOK, transacoes)
+}
+
+// CreatePortfolioTransaction godoc
+// @Summary Registrar transação
+// @Description Registra uma compra ou venda de ação, FII, ETF, BDR, Tesouro ou renda fixa. Para renda fixa use quantidade 1 e o valor aplicado como preço
+// @Tags portfolio
+// @Accept  json
+// @Produce  json
+// @Param X-User-ID header string true "Identificador do usuário"
+// @Param request body models.Transacao true "Dados da transação"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /portfolio/transactions [post]
+func CreatePortfolioTransaction(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var transacao models.Transacao
+	if err := c.ShouldBindJSON(&transacao); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Dados inválidos", "detalhes": err.Error()})
+		return
+	}
+
+	if err := validateTransacao(&transacao); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": err.Error()})
+		return
+	}
+
+	// Não permite vender mais do que a posição atual
+	if transacao.Operacao == models.OperacaoVenda {
+		transacoes, err := loadTransactions(userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar transações"})
+			return
+		}
+		posicoes, _ := replayTransactions(transacoes)
+		p, exists := posicoes[positionKey(transacao.TipoAtivo, transacao.Symbol)]
+		if !exists || p.quantidade+quantidadeMinima < transacao.Quantidade {
+			c.JSON(http.StatusBadRequest, gin.H{"erro": "Quantidade vendida maior que a posição atual"})
+			return
+		}
+	}
+
+	query := `INSERT INTO portfolio_transacoes (user_id, tipo_ativo, symbol, operacao, quantidade, preco, taxas, data)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`
+	var id int
+	err := config.DB.QueryRow(query, userID, transacao.TipoAtivo, transacao.Symbol, transacao.Operacao,
+		transacao.Quantidade, transacao.Preco, transacao.Taxas, transacao.Data).Scan(&id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao registrar transação"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"mensagem": "Transação registrada com sucesso", "id": id})
+}
+
+// DeletePortfolioTransaction godoc
+// @Summary Remover transação
+// @Description Remove uma transação da carteira do usuário
+// @Tags portfolio
+// @Accept  json
+// @Produce  json
+// @Param X-User-ID header string true "Identificador do usuário"
+// @Param id path int true "ID da transação"
+// @Success 200 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /portfolio/transactions/{id} [delete]
+func DeletePortfolioTransaction(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	result, err := config.DB.Exec("DELETE FROM portfolio_transacoes WHERE id = $1 AND user_id = $2", c.Param("id"), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao remover transação"})
+		return
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"erro": "Transação não encontrada"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"mensagem": "Transação removida com sucesso"})
+}
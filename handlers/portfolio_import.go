@@ -0,0 +1,289 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"go-br-finance-api/config"
+	"go-br-finance-api/importer"
+	"go-br-finance-api/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// Tamanho máximo do arquivo importado
+const maxImportSize = 10 << 20
+
+type ImportedTransacao struct {
+	models.Transacao
+	Duplicada bool `json:"duplicada"`
+}
+
+type ImportedProvento struct {
+	models.Provento
+	Duplicado bool `json:"duplicado"`
+}
+
+type ImportResponse struct {
+	Relatorio  string              `json:"relatorio"`
+	DryRun     bool                `json:"dry_run"`
+	Transacoes []ImportedTransacao `json:"transacoes"`
+	Proventos  []ImportedProvento  `json:"proventos"`
+	Novos      int                 `json:"novos"`
+	Duplicados int                 `json:"duplicados"`
+	Ignorados  int                 `json:"ignorados"`
+	Importados int                 `json:"importados"`
+}
+
+// importHash identifica um lançamento importado. A ocorrência diferencia negócios
+// idênticos no mesmo dia, mantendo o hash estável ao reimportar o mesmo período.
+func importHash(userID string, occurrence int, fields ...interface{}) string {
+	h := sha256.New()
+	fmt.Fprint(h, userID)
+	for _, f := range fields {
+		fmt.Fprintf(h, "|%v", f)
+	}
+	fmt.Fprintf(h, "|%d", occurrence)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// inferTipoAtivo deduz o tipo do ativo pelo código de negociação
+func inferTipoAtivo(codigo string, acoes map[string]bool, overrides map[string]string) string {
+	if tipo, ok := overrides[codigo]; ok {
+		return tipo
+	}
+	switch {
+	case strings.HasSuffix(codigo, "32"), strings.HasSuffix(codigo, "33"), strings.HasSuffix(codigo, "34"),
+		strings.HasSuffix(codigo, "35"), strings.HasSuffix(codigo, "39"):
+		return models.TipoBDR
+	case strings.HasSuffix(codigo, "11"):
+		// Units aparecem na lista de ações; os demais códigos 11 são tratados como FII
+		if acoes[codigo] {
+			return models.TipoAcao
+		}
+		return models.TipoFII
+	default:
+		return models.TipoAcao
+	}
+}
+
+// parseTipoOverrides interpreta "BOVA11=etf,IVVB11=etf"
+func parseTipoOverrides(s string) (map[string]string, error) {
+	overrides := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 || !tiposAtivo[strings.ToLower(strings.TrimSpace(parts[1]))] {
+			return nil, fmt.Errorf("tipo inválido em %q", pair)
+		}
+		overrides[strings.ToUpper(strings.TrimSpace(parts[0]))] = strings.ToLower(strings.TrimSpace(parts[1]))
+	}
+	return overrides, nil
+}
+
+// existingImportHashes retorna quais hashes já foram importados pelo usuário
+func existingImportHashes(table, userID string, hashes []string) (map[string]bool, error) {
+	existing := make(map[string]bool)
+	if len(hashes) == 0 {
+		return existing, nil
+	}
+
+	var found []string
+	query := fmt.Sprintf("SELECT import_hash FROM %s WHERE user_id = $1 AND import_hash = ANY($2)", table)
+	if err := config.DB.Select(&found, query, userID, pq.Array(hashes)); err != nil {
+		return nil, err
+	}
+	for _, h := range found {
+		existing[h] = true
+	}
+	return existing, nil
+}
+
+// ImportPortfolio godoc
+// @Summary Importar extrato da B3
+// @Description Importa os relatórios de negociação (compras e vendas) e de movimentação (proventos) da Área do Investidor da B3, em CSV ou XLSX, com deduplicação. Use dry_run=true para pré-visualizar
+// @Tags portfolio
+// @Accept  multipart/form-data
+// @Produce  json
+// @Param X-User-ID header string true "Identificador do usuário"
+// @Param file formData file true "Planilha exportada da Área do Investidor"
+// @Param dry_run query bool false "Apenas pré-visualizar, sem gravar" default(false)
+// @Param tipos query string false "Tipos de ativo que não podem ser deduzidos pelo código (ex: BOVA11=etf,IVVB11=etf)"
+// @Success 200 {object} ImportResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /portfolio/import [post]
+func ImportPortfolio(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	dryRun := c.DefaultQuery("dry_run", "false") == "true"
+	overrides, err := parseTipoOverrides(c.Query("tipos"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": err.Error()})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Arquivo obrigatório no campo 'file'"})
+		return
+	}
+	if fileHeader.Size > maxImportSize {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Arquivo maior que 10 MB"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Não foi possível abrir o arquivo"})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxImportSize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Não foi possível ler o arquivo"})
+		return
+	}
+
+	rows, err := importer.ReadSpreadsheet(data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": err.Error()})
+		return
+	}
+
+	parsed, err := importer.ParseB3(rows)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": err.Error()})
+		return
+	}
+
+	acoes := make(map[string]bool)
+	if stocks, err := loadStocks(context.Background()); err == nil {
+		for _, stock := range stocks {
+			acoes[stock.Symbol] = true
+		}
+	}
+
+	response := ImportResponse{
+		Relatorio:  parsed.Relatorio,
+		DryRun:     dryRun,
+		Transacoes: []ImportedTransacao{},
+		Proventos:  []ImportedProvento{},
+		Ignorados:  parsed.Ignoradas,
+	}
+
+	// Converte os lançamentos e calcula os hashes de deduplicação
+	var transacaoHashes, proventoHashes []string
+	occurrences := make(map[string]int)
+	for _, n := range parsed.Negociacoes {
+		key := fmt.Sprint(n.Data, n.Operacao, n.Codigo, n.Quantidade, n.Preco, n.Instituicao)
+		occurrences[key]++
+
+		response.Transacoes = append(response.Transacoes, ImportedTransacao{Transacao: models.Transacao{
+			TipoAtivo:  inferTipoAtivo(n.Codigo, acoes, overrides),
+			Symbol:     n.Codigo,
+			Operacao:   n.Operacao,
+			Quantidade: n.Quantidade,
+			Preco:      n.Preco,
+			Data:       n.Data,
+		}})
+		transacaoHashes = append(transacaoHashes, importHash(userID, occurrences[key], "negociacao", n.Data, n.Operacao, n.Codigo, n.Quantidade, n.Preco, n.Instituicao))
+	}
+
+	for _, p := range parsed.Proventos {
+		key := fmt.Sprint(p.Data, p.Tipo, p.Codigo, p.Valor, p.Instituicao)
+		occurrences[key]++
+
+		response.Proventos = append(response.Proventos, ImportedProvento{Provento: models.Provento{
+			Tipo:        p.Tipo,
+			TipoAtivo:   inferTipoAtivo(p.Codigo, acoes, overrides),
+			Symbol:      p.Codigo,
+			Valor:       p.Valor,
+			Data:        p.Data,
+			Instituicao: p.Instituicao,
+		}})
+		proventoHashes = append(proventoHashes, importHash(userID, occurrences[key], "provento", p.Data, p.Tipo, p.Codigo, p.Valor, p.Instituicao))
+	}
+
+	existingTransacoes, err := existingImportHashes("portfolio_transacoes", userID, transacaoHashes)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao verificar duplicidades"})
+		return
+	}
+	existingProventos, err := existingImportHashes("portfolio_proventos", userID, proventoHashes)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao verificar duplicidades"})
+		return
+	}
+
+	for i := range response.Transacoes {
+		response.Transacoes[i].Duplicada = existingTransacoes[transacaoHashes[i]]
+	}
+	for i := range response.Proventos {
+		response.Proventos[i].Duplicado = existingProventos[proventoHashes[i]]
+	}
+	response.Duplicados = len(existingTransacoes) + len(existingProventos)
+	response.Novos = len(response.Transacoes) + len(response.Proventos) - response.Duplicados
+
+	if dryRun {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	tx, err := config.DB.Beginx()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao importar lançamentos"})
+		return
+	}
+	defer tx.Rollback()
+
+	for i, t := range response.Transacoes {
+		if t.Duplicada {
+			continue
+		}
+		result, err := tx.Exec(`INSERT INTO portfolio_transacoes (user_id, tipo_ativo, symbol, operacao, quantidade, preco, taxas, data, import_hash)
+			VALUES ($1, $2, $3, $4, $5, $6, 0, $7, $8) ON CONFLICT (user_id, import_hash) DO NOTHING`,
+			userID, t.TipoAtivo, t.Symbol, t.Operacao, t.Quantidade, t.Preco, t.Data, transacaoHashes[i])
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao importar transações"})
+			return
+		}
+		rowsAffected, _ := result.RowsAffected()
+		response.Importados += int(rowsAffected)
+	}
+
+	for i, p := range response.Proventos {
+		if p.Duplicado {
+			continue
+		}
+		result, err := tx.Exec(`INSERT INTO portfolio_proventos (user_id, tipo, tipo_ativo, symbol, valor, data, instituicao, import_hash)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8) ON CONFLICT (user_id, import_hash) DO NOTHING`,
+			userID, p.Tipo, p.TipoAtivo, p.Symbol, p.Valor, p.Data, p.Instituicao, proventoHashes[i])
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao importar proventos"})
+			return
+		}
+		rowsAffected, _ := result.RowsAffected()
+		response.Importados += int(rowsAffected)
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao importar lançamentos"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"go-br-finance-api/config"
+	"go-br-finance-api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const proventoColumns = `id, user_id, tipo, tipo_ativo, symbol, valor, to_char(data, 'YYYY-MM-DD') AS data, instituicao, created_at`
+
+var tiposProvento = map[string]bool{
+	models.ProventoDividendo:  true,
+	models.ProventoJCP:        true,
+	models.ProventoRendimento: true,
+}
+
+// loadProventos retorna os proventos do usuário em ordem cronológica
+func loadProventos(userID string) ([]models.Provento, error) {
+	var proventos []models.Provento
+	query := "SELECT " + proventoColumns + " FROM portfolio_proventos WHERE user_id = $1 ORDER BY data, id"
+	err := config.DB.Select(&proventos, query, userID)
+	return proventos, err
+}
+
+// GetProventos godoc
+// @Summary Listar proventos
+// @Description Lista dividendos, JCP e rendimentos recebidos pelo usuário
+// @Tags portfolio
+// @Accept  json
+// @Produce  json
+// @Param X-User-ID header string true "Identificador do usuário"
+// @Success 200 {array} models.Provento
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /portfolio/income [get]
+func GetProventos(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	proventos, err := loadProventos(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar proventos"})
+		return
+	}
+
+	c.JSON(http.StatusOK, proventos)
+}
+
+// CreateProvento godoc
+// @Summary Registrar provento
+// @Description Registra um dividendo, JCP ou rendimento recebido (valor líquido)
+// @Tags portfolio
+// @Accept  json
+// @Produce  json
+// @Param X-User-ID header string true "Identificador do usuário"
+// @Param request body models.Provento true "Dados do provento"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /portfolio/income [post]
+func CreateProvento(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var provento models.Provento
+	if err := c.ShouldBindJSON(&provento); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Dados inválidos", "detalhes": err.Error()})
+		return
+	}
+
+	provento.Tipo = strings.ToLower(strings.TrimSpace(provento.Tipo))
+	provento.TipoAtivo = strings.ToLower(strings.TrimSpace(provento.TipoAtivo))
+	provento.Symbol = strings.ToUpper(strings.TrimSpace(provento.Symbol))
+
+	if !tiposProvento[provento.Tipo] {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "tipo deve ser dividendo, jcp ou rendimento"})
+		return
+	}
+	if !tiposAtivo[provento.TipoAtivo] || provento.Symbol == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "tipo_ativo e symbol são obrigatórios"})
+		return
+	}
+	if provento.Valor <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "valor deve ser positivo"})
+		return
+	}
+	if _, err := time.Parse(dateLayout, provento.Data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "data deve estar no formato YYYY-MM-DD"})
+		return
+	}
+
+	query := `INSERT INTO portfolio_proventos (user_id, tipo, tipo_ativo, symbol, valor, data, instituicao)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
+	var id int
+	err := config.DB.QueryRow(query, userID, provento.Tipo, provento.TipoAtivo, provento.Symbol,
+		provento.Valor, provento.Data, provento.Instituicao).Scan(&id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao registrar provento"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"mensagem": "Provento registrado com sucesso", "id": id})
+}
+
+// DeleteProvento godoc
+// @Summary Remover provento
+// @Description Remove um provento registrado pelo usuário
+// @Tags portfolio
+// @Accept  json
+// @Produce  json
+// @Param X-User-ID header string true "Identificador do usuário"
+// @Param id path int true "ID do provento"
+// @Success 200 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /portfolio/income/{id} [delete]
+func DeleteProvento(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	result, err := config.DB.Exec("DELETE FROM portfolio_proventos WHERE id = $1 AND user_id = $2", c.Param("id"), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao remover provento"})
+		return
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"erro": "Provento não encontrado"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"mensagem": "Provento removido com sucesso"})
+}
@@ -0,0 +1,379 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go-br-finance-api/config"
+
+	"github.com/lib/pq"
+)
+
+// Content sources indexed for retrieval
+const (
+	fonteRecomendacao = "recomendacao"
+	fonteArtigo       = "artigo"
+
+	// Approximate size in characters of each indexed passage
+	ragChunkSize = 800
+)
+
+type ragDocument struct {
+	fonte   string
+	fonteID int
+	titulo  string
+	texto   string
+}
+
+type ragPassage struct {
+	Fonte     string
+	FonteID   int
+	Titulo    string
+	Trecho    string
+	Score     float64
+	embedding []float64
+}
+
+// contentIndex keeps the passage embeddings in memory; it is reloaded after each indexing run
+var contentIndex = struct {
+	sync.RWMutex
+	passages []ragPassage
+}{}
+
+var reindexRequests = make(chan struct{}, 1)
+
+func embedModel() string {
+	if model := os.Getenv("OLLAMA_EMBED_MODEL"); model != "" {
+		return model
+	}
+	return "nomic-embed-text"
+}
+
+func envInt(name string, fallback int) int {
+	if v, err := strconv.Atoi(os.Getenv(name)); err == nil && v > 0 {
+		return v
+	}
+	return fallback
+}
+
+func envFloat(name string, fallback float64) float64 {
+	if v, err := strconv.ParseFloat(os.Getenv(name), 64); err == nil {
+		return v
+	}
+	return fallback
+}
+
+// ollamaEmbed returns one embedding per input using Ollama's /api/embed endpoint
+func ollamaEmbed(inputs []string) ([][]float64, error) {
+	httpReq, err := newOllamaRequest("/api/embed", map[string]interface{}{
+		"model": embedModel(),
+		"input": inputs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{Timeout: time.Minute}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ollama embed returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Embeddings [][]float64 `json:"embeddings"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	if len(result.Embeddings) != len(inputs) {
+		return nil, fmt.Errorf("ollama returned %d embeddings for %d inputs", len(result.Embeddings), len(inputs))
+	}
+	return result.Embeddings, nil
+}
+
+// chunkText splits the text into passages of about size characters, breaking on paragraphs and sentences
+func chunkText(text string, size int) []string {
+	var chunks []string
+	var current strings.Builder
+
+	flush := func() {
+		if s := strings.TrimSpace(current.String()); s != "" {
+			chunks = append(chunks, s)
+		}
+		current.Reset()
+	}
+
+	for _, paragraph := range strings.Split(text, "\n") {
+		for _, sentence := range strings.SplitAfter(paragraph, ". ") {
+			if current.Len() > 0 && current.Len()+len(sentence) > size {
+				flush()
+			}
+			current.WriteString(sentence)
+		}
+		current.WriteString("\n")
+	}
+	flush()
+	return chunks
+}
+
+func loadRAGDocuments() ([]ragDocument, error) {
+	var recomendacoes []struct {
+		ID        int    `db:"id"`
+		Titulo    string `db:"titulo"`
+		Descricao string `db:"descricao"`
+	}
+	if err := config.DB.Select(&recomendacoes, "SELECT id, titulo, descricao FROM recomendacoes_financeiras"); err != nil {
+		return nil, err
+	}
+
+	var artigos []struct {
+		ID       int    `db:"id"`
+		Titulo   string `db:"titulo"`
+		Conteudo string `db:"conteudo"`
+	}
+	if err := config.DB.Select(&artigos, "SELECT id, titulo, conteudo FROM artigos"); err != nil {
+		return nil, err
+	}
+
+	docs := make([]ragDocument, 0, len(recomendacoes)+len(artigos))
+	for _, r := range recomendacoes {
+		docs = append(docs, ragDocument{fonte: fonteRecomendacao, fonteID: r.ID, titulo: r.Titulo, texto: r.Descricao})
+	}
+	for _, a := range artigos {
+		docs = append(docs, ragDocument{fonte: fonteArtigo, fonteID: a.ID, titulo: a.Titulo, texto: a.Conteudo})
+	}
+	return docs, nil
+}
+
+func contentHash(doc ragDocument, model string) string {
+	h := sha256.Sum256([]byte(model + "\x00" + doc.titulo + "\x00" + doc.texto))
+	return hex.EncodeToString(h[:])
+}
+
+// indexContent embeds new or changed recommendations and articles, removes deleted ones
+// and reloads the in-memory index
+func indexContent() error {
+	docs, err := loadRAGDocuments()
+	if err != nil {
+		return err
+	}
+
+	var indexed []struct {
+		Fonte   string `db:"fonte"`
+		FonteID int    `db:"fonte_id"`
+		Hash    string `db:"conteudo_hash"`
+	}
+	if err := config.DB.Select(&indexed, "SELECT fonte, fonte_id, conteudo_hash FROM conteudo_embeddings WHERE trecho_indice = 0"); err != nil {
+		return err
+	}
+	hashes := make(map[string]string, len(indexed))
+	for _, i := range indexed {
+		hashes[fmt.Sprintf("%s:%d", i.Fonte, i.FonteID)] = i.Hash
+	}
+
+	model := embedModel()
+	failed := 0
+	ids := map[string][]int64{fonteRecomendacao: {}, fonteArtigo: {}}
+	for _, doc := range docs {
+		ids[doc.fonte] = append(ids[doc.fonte], int64(doc.fonteID))
+
+		hash := contentHash(doc, model)
+		if hashes[fmt.Sprintf("%s:%d", doc.fonte, doc.fonteID)] == hash {
+			continue
+		}
+
+		// One document failing (e.g. an embedding error) shouldn't keep the others from being indexed
+		if err := indexDocument(doc, hash, model); err != nil {
+			log.Printf("⚠️  Erro ao indexar %s #%d: %v", doc.fonte, doc.fonteID, err)
+			failed++
+		}
+	}
+
+	for fonte, fonteIDs := range ids {
+		if _, err := config.DB.Exec("DELETE FROM conteudo_embeddings WHERE fonte = $1 AND fonte_id <> ALL($2)", fonte, pq.Array(fonteIDs)); err != nil {
+			return err
+		}
+	}
+
+	if err := reloadContentIndex(model); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d documentos não indexados", failed)
+	}
+	return nil
+}
+
+// indexDocument replaces the chunks and embeddings stored for the document
+func indexDocument(doc ragDocument, hash, model string) error {
+	chunks := chunkText(doc.titulo+"\n"+doc.texto, ragChunkSize)
+	embeddings, err := ollamaEmbed(chunks)
+	if err != nil {
+		return err
+	}
+
+	tx, err := config.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM conteudo_embeddings WHERE fonte = $1 AND fonte_id = $2", doc.fonte, doc.fonteID); err != nil {
+		return err
+	}
+	for i, chunk := range chunks {
+		_, err := tx.Exec(`INSERT INTO conteudo_embeddings (fonte, fonte_id, trecho_indice, titulo, trecho, conteudo_hash, modelo, embedding)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`, doc.fonte, doc.fonteID, i, doc.titulo, chunk, hash, model, pq.Array(embeddings[i]))
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func reloadContentIndex(model string) error {
+	var rows []struct {
+		Fonte     string          `db:"fonte"`
+		FonteID   int             `db:"fonte_id"`
+		Titulo    string          `db:"titulo"`
+		Trecho    string          `db:"trecho"`
+		Embedding pq.Float64Array `db:"embedding"`
+	}
+	query := "SELECT fonte, fonte_id, titulo, trecho, embedding FROM conteudo_embeddings WHERE modelo = $1 ORDER BY fonte, fonte_id, trecho_indice"
+	if err := config.DB.Select(&rows, query, model); err != nil {
+		return err
+	}
+
+	passages := make([]ragPassage, 0, len(rows))
+	for _, r := range rows {
+		passages = append(passages, ragPassage{Fonte: r.Fonte, FonteID: r.FonteID, Titulo: r.Titulo, Trecho: r.Trecho, embedding: r.Embedding})
+	}
+
+	contentIndex.Lock()
+	contentIndex.passages = passages
+	contentIndex.Unlock()
+	return nil
+}
+
+// requestReindex schedules an indexing run without blocking the caller
+func requestReindex() {
+	select {
+	case reindexRequests <- struct{}{}:
+	default:
+	}
+}
+
+// StartContentIndexer indexes the content at startup, periodically and whenever it changes
+func StartContentIndexer(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if err := indexContent(); err != nil {
+				log.Println("⚠️  Erro ao indexar conteúdo do chat:", err)
+			}
+
+			select {
+			case <-ticker.C:
+			case <-reindexRequests:
+			}
+		}
+	}()
+}
+
+func cosineSimilarity(a, b []float64) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// retrievePassages returns the passages most similar to the query, best first
+func retrievePassages(query string) ([]ragPassage, error) {
+	contentIndex.RLock()
+	passages := contentIndex.passages
+	contentIndex.RUnlock()
+	if len(passages) == 0 {
+		return nil, nil
+	}
+
+	embeddings, err := ollamaEmbed([]string{query})
+	if err != nil {
+		return nil, err
+	}
+
+	minScore := envFloat("RAG_MIN_SCORE", 0.5)
+	var matches []ragPassage
+	for _, p := range passages {
+		if score := cosineSimilarity(embeddings[0], p.embedding); score >= minScore {
+			p.Score = score
+			matches = append(matches, p)
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+	if topK := envInt("RAG_TOP_K", 3); len(matches) > topK {
+		matches = matches[:topK]
+	}
+	return matches, nil
+}
+
+// passageLabel names the source of a passage for citations
+func passageLabel(p ragPassage) string {
+	if p.Fonte == fonteRecomendacao {
+		return fmt.Sprintf("Recomendação #%d: %s", p.FonteID, p.Titulo)
+	}
+	return fmt.Sprintf("Artigo #%d: %s", p.FonteID, p.Titulo)
+}
+
+// passagesPrompt builds the system message with the retrieved content, labeled [R1], [R2]...
+func passagesPrompt(passages []ragPassage) string {
+	var b strings.Builder
+	b.WriteString("Recomendações e artigos da base de conhecimento relevantes para a pergunta. Baseie-se neles quando aplicável e cite a fonte pelo rótulo, ex: [R1].\n")
+	for i, p := range passages {
+		fmt.Fprintf(&b, "\n[R%d] %s\n%s\n", i+1, passageLabel(p), p.Trecho)
+	}
+	return b.String()
+}
+
+// formatPassages lists the content passages cited in the answer, in the same format as the web sources
+func formatPassages(passages []ragPassage, answer string) string {
+	var b strings.Builder
+	for i, p := range passages {
+		label := fmt.Sprintf("[R%d]", i+1)
+		if !strings.Contains(answer, label) {
+			continue
+		}
+		if b.Len() == 0 {
+			b.WriteString("\n\nConteúdo citado:")
+		}
+		fmt.Fprintf(&b, "\n%s %s", label, passageLabel(p))
+	}
+	return b.String()
+}
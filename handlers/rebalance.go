@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"sort"
+
+	"go-br-finance-api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Classes usadas na alocação alvo e os tipos de ativo que compõem cada uma
+var classesRebalanceamento = map[string][]string{
+	"acoes":      {models.TipoAcao},
+	"fiis":       {models.TipoFII},
+	"etfs":       {models.TipoETF},
+	"exterior":   {models.TipoBDR},
+	"renda_fixa": {models.TipoTesouro, models.TipoRendaFixa},
+}
+
+type RebalanceRequest struct {
+	Alvo   map[string]float64 `json:"alvo" binding:"required"`   // percentual por classe, somando 100
+	Aporte float64            `json:"aporte" binding:"required"` // valor do novo aporte
+}
+
+type RebalanceClasse struct {
+	Classe          string  `json:"classe"`
+	AlvoPercentual  float64 `json:"alvo_percentual"`
+	ValorAtual      float64 `json:"valor_atual"`
+	PercentualAtual float64 `json:"percentual_atual"`
+	Aporte          float64 `json:"aporte"`
+	ValorFinal      float64 `json:"valor_final"`
+	PercentualFinal float64 `json:"percentual_final"`
+}
+
+type RebalanceCompra struct {
+	Classe     string   `json:"classe"`
+	Symbol     string   `json:"symbol,omitempty"` // vazio quando a classe ainda não tem ativos
+	Quantidade *float64 `json:"quantidade,omitempty"`
+	Preco      float64  `json:"preco,omitempty"`
+	Valor      float64  `json:"valor"`
+}
+
+type RebalanceResponse struct {
+	Aporte  float64           `json:"aporte"`
+	Classes []RebalanceClasse `json:"classes"`
+	Compras []RebalanceCompra `json:"compras"`
+	Sobra   float64           `json:"sobra"` // valor não alocado por arredondamento de cotas
+}
+
+func classeDoTipo(tipoAtivo string) string {
+	for classe, tipos := range classesRebalanceamento {
+		for _, t := range tipos {
+			if t == tipoAtivo {
+				return classe
+			}
+		}
+	}
+	return ""
+}
+
+// distribuirAporte divide o aporte entre as classes proporcionalmente ao quanto cada uma
+// está abaixo do alvo após o aporte, sem vender nada
+func distribuirAporte(atual, alvo map[string]float64, aporte float64) map[string]float64 {
+	var total float64
+	for _, v := range atual {
+		total += v
+	}
+	totalFinal := total + aporte
+
+	deficits := make(map[string]float64)
+	var somaDeficits float64
+	for classe, percentual := range alvo {
+		if d := percentual/100*totalFinal - atual[classe]; d > 0 {
+			deficits[classe] = d
+			somaDeficits += d
+		}
+	}
+
+	distribuicao := make(map[string]float64)
+	for classe, d := range deficits {
+		distribuicao[classe] = aporte * d / somaDeficits
+	}
+	return distribuicao
+}
+
+// RebalancePortfolio godoc
+// @Summary Sugestão de rebalanceamento
+// @Description Dada uma alocação alvo por classe (acoes, fiis, etfs, exterior, renda_fixa) e um novo aporte, sugere o que comprar para se aproximar do alvo sem vender
+// @Tags portfolio
+// @Accept  json
+// @Produce  json
+// @Param X-User-ID header string true "Identificador do usuário"
+// @Param request body RebalanceRequest true "Alocação alvo e valor do aporte"
+// @Success 200 {object} RebalanceResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /portfolio/rebalance [post]
+func RebalancePortfolio(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req RebalanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Dados inválidos", "detalhes": err.Error()})
+		return
+	}
+
+	if req.Aporte <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "aporte deve ser positivo"})
+		return
+	}
+
+	var soma float64
+	for classe, percentual := range req.Alvo {
+		if _, ok := classesRebalanceamento[classe]; !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"erro": "Classe desconhecida: " + classe + " (use acoes, fiis, etfs, exterior ou renda_fixa)"})
+			return
+		}
+		if percentual < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"erro": "Percentuais não podem ser negativos"})
+			return
+		}
+		soma += percentual
+	}
+	if math.Abs(soma-100) > 0.01 {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "A alocação alvo deve somar 100%"})
+		return
+	}
+
+	carteira, err := buildCarteira(context.Background(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao calcular carteira"})
+		return
+	}
+
+	atual := make(map[string]float64)
+	posicoesPorClasse := make(map[string][]models.Posicao)
+	for _, p := range carteira.Posicoes {
+		classe := classeDoTipo(p.TipoAtivo)
+		atual[classe] += p.ValorAtual
+		posicoesPorClasse[classe] = append(posicoesPorClasse[classe], p)
+	}
+
+	distribuicao := distribuirAporte(atual, req.Alvo, req.Aporte)
+	response := RebalanceResponse{Aporte: req.Aporte, Classes: []RebalanceClasse{}, Compras: []RebalanceCompra{}}
+	totalFinal := carteira.ValorTotal + req.Aporte
+
+	classes := make(map[string]bool)
+	for classe := range req.Alvo {
+		classes[classe] = true
+	}
+	for classe := range atual {
+		classes[classe] = true
+	}
+
+	for classe := range classes {
+		valor := distribuicao[classe]
+		resumo := RebalanceClasse{
+			Classe:         classe,
+			AlvoPercentual: req.Alvo[classe],
+			ValorAtual:     atual[classe],
+			Aporte:         valor,
+			ValorFinal:     atual[classe] + valor,
+		}
+		if carteira.ValorTotal > 0 {
+			resumo.PercentualAtual = atual[classe] / carteira.ValorTotal * 100
+		}
+		resumo.PercentualFinal = resumo.ValorFinal / totalFinal * 100
+		response.Classes = append(response.Classes, resumo)
+
+		if valor <= 0 {
+			continue
+		}
+
+		// Divide o valor da classe igualmente entre os ativos cotados que o usuário já possui
+		var cotados []models.Posicao
+		for _, p := range posicoesPorClasse[classe] {
+			if p.FontePreco == "cotacao" {
+				cotados = append(cotados, p)
+			}
+		}
+		if len(cotados) == 0 {
+			response.Compras = append(response.Compras, RebalanceCompra{Classe: classe, Valor: valor})
+			continue
+		}
+
+		parcela := valor / float64(len(cotados))
+		for _, p := range cotados {
+			quantidade := math.Floor(parcela / p.PrecoAtual)
+			response.Sobra += parcela - quantidade*p.PrecoAtual
+			if quantidade == 0 {
+				continue
+			}
+			response.Compras = append(response.Compras, RebalanceCompra{
+				Classe:     classe,
+				Symbol:     p.Symbol,
+				Quantidade: &quantidade,
+				Preco:      p.PrecoAtual,
+				Valor:      quantidade * p.PrecoAtual,
+			})
+		}
+	}
+
+	sort.Slice(response.Classes, func(i, j int) bool { return response.Classes[i].Classe < response.Classes[j].Classe })
+	sort.SliceStable(response.Compras, func(i, j int) bool { return response.Compras[i].Valor > response.Compras[j].Valor })
+
+	c.JSON(http.StatusOK, response)
+}
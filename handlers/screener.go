@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go-br-finance-api/config"
+	"go-br-finance-api/search"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ScreenerRequest struct {
+	Filters []string `json:"filters"` // ex: "dividend_yield > 6", "sector = Finance"
+	Sort    string   `json:"sort"`    // campo de ordenação
+	Order   string   `json:"order"`   // "asc" ou "desc"
+	Page    int      `json:"page"`    // começa em 1
+	Limit   int      `json:"limit"`   // itens por página
+}
+
+type ScreenerRow struct {
+	Symbol              string   `db:"symbol" json:"symbol"`
+	Name                string   `db:"name" json:"name"`
+	Sector              string   `db:"sector" json:"sector"`
+	Price               float64  `db:"close" json:"close"`
+	Change              float64  `db:"change" json:"change"`
+	Volume              int64    `db:"volume" json:"volume"`
+	PL                  *float64 `db:"pl" json:"pl"`
+	PVP                 *float64 `db:"pvp" json:"pvp"`
+	ROE                 *float64 `db:"roe" json:"roe"`
+	MargemLiquida       *float64 `db:"margem_liquida" json:"margem_liquida"`
+	DividaLiquidaEbitda *float64 `db:"divida_liquida_ebitda" json:"divida_liquida_ebitda"`
+	DividendYield       *float64 `db:"dividend_yield" json:"dividend_yield"`
+	EvEbitda            *float64 `db:"ev_ebitda" json:"ev_ebitda"`
+}
+
+type ScreenerResponse struct {
+	Total      int           `json:"total"`
+	Page       int           `json:"page"`
+	Limit      int           `json:"limit"`
+	Resultados []ScreenerRow `json:"resultados"`
+}
+
+type screenerFilter struct {
+	field string
+	op    string
+	num   float64
+	text  string
+}
+
+var screenerFilterPattern = regexp.MustCompile(`^\s*([a-z_]+)\s*(>=|<=|!=|=|>|<)\s*(.+?)\s*$`)
+
+// Campos numéricos aceitos pelo screener
+var screenerNumericFields = map[string]func(r ScreenerRow) *float64{
+	"close":                 func(r ScreenerRow) *float64 { return &r.Price },
+	"change":                func(r ScreenerRow) *float64 { return &r.Change },
+	"volume":                func(r ScreenerRow) *float64 { v := float64(r.Volume); return &v },
+	"pl":                    func(r ScreenerRow) *float64 { return r.PL },
+	"pvp":                   func(r ScreenerRow) *float64 { return r.PVP },
+	"roe":                   func(r ScreenerRow) *float64 { return r.ROE },
+	"margem_liquida":        func(r ScreenerRow) *float64 { return r.MargemLiquida },
+	"divida_liquida_ebitda": func(r ScreenerRow) *float64 { return r.DividaLiquidaEbitda },
+	"dividend_yield":        func(r ScreenerRow) *float64 { return r.DividendYield },
+	"ev_ebitda":             func(r ScreenerRow) *float64 { return r.EvEbitda },
+}
+
+// Campos de texto aceitos pelo screener (somente = e !=); o setor é o retornado pela brapi.dev, em inglês
+var screenerTextFields = map[string]func(r ScreenerRow) string{
+	"symbol": func(r ScreenerRow) string { return r.Symbol },
+	"name":   func(r ScreenerRow) string { return r.Name },
+	"sector": func(r ScreenerRow) string { return r.Sector },
+}
+
+// parseScreenerFilter interpreta expressões como "pl < 10" ou "sector = Finance"
+func parseScreenerFilter(expr string) (screenerFilter, error) {
+	m := screenerFilterPattern.FindStringSubmatch(strings.ToLower(expr))
+	if m == nil {
+		return screenerFilter{}, fmt.Errorf("filtro inválido: %q", expr)
+	}
+
+	filter := screenerFilter{field: m[1], op: m[2]}
+	value := strings.Trim(m[3], `"'`)
+
+	if _, ok := screenerNumericFields[filter.field]; ok {
+		num, err := strconv.ParseFloat(strings.ReplaceAll(value, ",", "."), 64)
+		if err != nil {
+			return screenerFilter{}, fmt.Errorf("valor numérico inválido em %q", expr)
+		}
+		filter.num = num
+		return filter, nil
+	}
+
+	if _, ok := screenerTextFields[filter.field]; ok {
+		if filter.op != "=" && filter.op != "!=" {
+			return screenerFilter{}, fmt.Errorf("campo %s aceita apenas = e !=", filter.field)
+		}
+		filter.text = search.Fold(value)
+		return filter, nil
+	}
+
+	return screenerFilter{}, fmt.Errorf("campo desconhecido: %s", filter.field)
+}
+
+func (f screenerFilter) match(row ScreenerRow) bool {
+	if getter, ok := screenerTextFields[f.field]; ok {
+		equal := search.Fold(getter(row)) == f.text
+		return equal == (f.op == "=")
+	}
+
+	value := screenerNumericFields[f.field](row)
+	if value == nil {
+		// Indicador ausente nunca satisfaz um filtro numérico
+		return false
+	}
+
+	switch f.op {
+	case ">":
+		return *value > f.num
+	case ">=":
+		return *value >= f.num
+	case "<":
+		return *value < f.num
+	case "<=":
+		return *value <= f.num
+	case "=":
+		return *value == f.num
+	default:
+		return *value != f.num
+	}
+}
+
+// loadScreenerDataset junta a última cotação com o último trimestre de indicadores de cada ação.
+// Os indicadores de todas as ações de cotacoes são atualizados por StartFundamentalsRefresher
+func loadScreenerDataset() ([]ScreenerRow, error) {
+	query := `SELECT c.symbol, c.name, c.sector, c.close, c.change, c.volume,
+			f.pl, f.pvp, f.roe, f.margem_liquida, f.divida_liquida_ebitda, f.dividend_yield, f.ev_ebitda
+		FROM cotacoes c
+		LEFT JOIN LATERAL (
+			SELECT * FROM fundamentos
+			WHERE fundamentos.symbol = c.symbol
+			ORDER BY ano DESC, trimestre DESC
+			LIMIT 1
+		) f ON true
+		ORDER BY c.symbol`
+
+	var rows []ScreenerRow
+	err := config.DB.Select(&rows, query)
+	return rows, err
+}
+
+// ScreenStocks godoc
+// @Summary Screener de ações
+// @Description Filtra ações usando cotações e indicadores armazenados localmente. Filtros no formato "campo operador valor" (ex: "dividend_yield > 6", "pl < 10", "sector = Finance"). O setor vem da brapi.dev, em inglês, e os indicadores de todas as ações são atualizados diariamente em segundo plano
+// @Tags stocks
+// @Accept  json
+// @Produce  json
+// @Param request body ScreenerRequest true "Filtros, ordenação e paginação"
+// @Success 200 {object} ScreenerResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /stocks/screener [post]
+func ScreenStocks(c *gin.Context) {
+	var req ScreenerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Dados inválidos", "detalhes": err.Error()})
+		return
+	}
+
+	var filters []screenerFilter
+	for _, expr := range req.Filters {
+		filter, err := parseScreenerFilter(expr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"erro": err.Error()})
+			return
+		}
+		filters = append(filters, filter)
+	}
+
+	sortField := strings.ToLower(req.Sort)
+	if sortField == "" {
+		sortField = "symbol"
+	}
+	_, numericSort := screenerNumericFields[sortField]
+	_, textSort := screenerTextFields[sortField]
+	if !numericSort && !textSort {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Campo de ordenação desconhecido: " + req.Sort})
+		return
+	}
+	desc := strings.ToLower(req.Order) == "desc"
+
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.Limit < 1 || req.Limit > 100 {
+		req.Limit = 20
+	}
+
+	dataset, err := loadScreenerDataset()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao carregar dados do screener"})
+		return
+	}
+
+	resultados := []ScreenerRow{}
+	for _, row := range dataset {
+		matched := true
+		for _, filter := range filters {
+			if !filter.match(row) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			resultados = append(resultados, row)
+		}
+	}
+
+	sort.SliceStable(resultados, func(i, j int) bool {
+		if textSort {
+			a, b := screenerTextFields[sortField](resultados[i]), screenerTextFields[sortField](resultados[j])
+			if desc {
+				return a > b
+			}
+			return a < b
+		}
+
+		a, b := screenerNumericFields[sortField](resultados[i]), screenerNumericFields[sortField](resultados[j])
+		// Valores ausentes sempre ao final
+		if a == nil || b == nil {
+			return a != nil
+		}
+		if desc {
+			return *a > *b
+		}
+		return *a < *b
+	})
+
+	total := len(resultados)
+	start := (req.Page - 1) * req.Limit
+	if start > total {
+		start = total
+	}
+	end := start + req.Limit
+	if end > total {
+		end = total
+	}
+
+	c.JSON(http.StatusOK, ScreenerResponse{
+		Total:      total,
+		Page:       req.Page,
+		Limit:      req.Limit,
+		Resultados: resultados[start:end],
+	})
+}
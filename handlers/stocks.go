@@ -4,37 +4,83 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"go-br-finance-api/cache"
 	"go-br-finance-api/config"
+	"go-br-finance-api/search"
+	"log"
 	"net/http"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
 )
 
 type Stock struct {
 	Symbol string  `json:"stock"`
 	Name   string  `json:"name"`
 	Price  float64 `json:"close"`
+	Change float64 `json:"change"`
+	Volume int64   `json:"volume"`
+	Sector string  `json:"sector"`
 }
 
 type BrapiResponse struct {
 	Stocks []Stock `json:"stocks"`
 }
 
-// GetStocks godoc
-// @Summary Get Brazilian stocks information
-// @Description Retrieve a list of all Brazilian stocks from B3 market using brapi.dev API
-// @Tags stocks
-// @Accept  json
-// @Produce  json
-// @Success 200 {array} Stock
-// @Router /stocks [get]
-func GetStocks(c *gin.Context) {
-	ctx := context.Background()
+// stockCatalog keeps the stock list together with its search index
+type stockCatalog struct {
+	Stocks []Stock
+	Index  *search.Index
+}
+
+const stockCatalogCacheKey = "stocks_catalog"
+
+// tickerPattern accepts B3 tickers (PETR4, BOVA11, ^BVSP) and nothing that could break a brapi URL
+var tickerPattern = regexp.MustCompile(`^\^?[A-Z0-9][A-Z0-9.]{0,11}$`)
+
+// validTicker reports whether the upper-cased symbol is safe to send to brapi
+func validTicker(symbol string) bool {
+	return tickerPattern.MatchString(symbol)
+}
 
+// loadStocks returns the full stock list, served from Redis when cached and
+// fetched from brapi.dev otherwise.
+func loadStocks(ctx context.Context) ([]Stock, error) {
+	catalog, err := loadStockCatalog(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return catalog.Stocks, nil
+}
+
+// loadStockCatalog returns the stock list and its search index, rebuilding the
+// index whenever the list is (re)loaded into memory.
+func loadStockCatalog(ctx context.Context) (*stockCatalog, error) {
+	if cachedData, found := cache.GlobalCache.Get(stockCatalogCacheKey); found {
+		return cachedData.(*stockCatalog), nil
+	}
+
+	allStocks, err := fetchStocks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]search.Entry, len(allStocks))
+	for i, stock := range allStocks {
+		entries[i] = search.Entry{Key: stock.Symbol, Text: stock.Name}
+	}
+
+	catalog := &stockCatalog{Stocks: allStocks, Index: search.NewIndex(entries)}
+	cache.GlobalCache.Set(stockCatalogCacheKey, catalog, 5*time.Minute)
+	return catalog, nil
+}
+
+func fetchStocks(ctx context.Context) ([]Stock, error) {
 	// Check cache
 	var allStocks []Stock
 	if config.RedisClient != nil {
@@ -43,49 +89,105 @@ func GetStocks(c *gin.Context) {
 			json.Unmarshal([]byte(cached), &allStocks)
 		}
 	}
+	if len(allStocks) > 0 {
+		return allStocks, nil
+	}
 
 	// If not cached, fetch all
-	if len(allStocks) == 0 {
-		url := fmt.Sprintf("https://brapi.dev/api/quote/list?limit=1000&type=stock&token=%s", os.Getenv("BRAPI_TOKEN"))
+	url := fmt.Sprintf("https://brapi.dev/api/quote/list?limit=1000&type=stock&token=%s", os.Getenv("BRAPI_TOKEN"))
 
-		resp, err := http.Get(url)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch data from brapi.dev"})
-			return
-		}
-		defer resp.Body.Close()
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch data from brapi.dev")
+	}
+	defer resp.Body.Close()
 
-		if resp.StatusCode != 200 {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "brapi.dev API returned error"})
-			return
-		}
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("brapi.dev API returned error")
+	}
 
-		var brapiResp BrapiResponse
-		if err := json.NewDecoder(resp.Body).Decode(&brapiResp); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse response"})
-			return
-		}
+	var brapiResp BrapiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&brapiResp); err != nil {
+		return nil, fmt.Errorf("Failed to parse response")
+	}
 
-		allStocks = brapiResp.Stocks
+	allStocks = brapiResp.Stocks
 
-		// Cache the data
-		if config.RedisClient != nil {
-			data, _ := json.Marshal(allStocks)
-			config.RedisClient.Set(ctx, "stocks", data, 30*time.Minute)
-		}
+	// Cache the data
+	if config.RedisClient != nil {
+		data, _ := json.Marshal(allStocks)
+		config.RedisClient.Set(ctx, "stocks", data, 30*time.Minute)
+	}
+
+	// Keep a local copy of the quotes for the screener
+	if err := saveQuotes(allStocks); err != nil {
+		log.Println("⚠️  Erro ao gravar cotações:", err)
+	}
+
+	return allStocks, nil
+}
+
+// saveQuotes upserts the latest quote of every stock into Postgres
+func saveQuotes(stocks []Stock) error {
+	if config.DB == nil || len(stocks) == 0 {
+		return nil
+	}
+
+	symbols := make([]string, len(stocks))
+	names := make([]string, len(stocks))
+	sectors := make([]string, len(stocks))
+	prices := make([]float64, len(stocks))
+	changes := make([]float64, len(stocks))
+	volumes := make([]int64, len(stocks))
+	for i, stock := range stocks {
+		symbols[i] = stock.Symbol
+		names[i] = stock.Name
+		sectors[i] = stock.Sector
+		prices[i] = stock.Price
+		changes[i] = stock.Change
+		volumes[i] = stock.Volume
+	}
+
+	query := `INSERT INTO cotacoes (symbol, name, sector, close, change, volume)
+		SELECT * FROM unnest($1::text[], $2::text[], $3::text[], $4::float8[], $5::float8[], $6::bigint[])
+		ON CONFLICT (symbol) DO UPDATE SET
+			name = EXCLUDED.name,
+			sector = EXCLUDED.sector,
+			close = EXCLUDED.close,
+			change = EXCLUDED.change,
+			volume = EXCLUDED.volume,
+			updated_at = CURRENT_TIMESTAMP`
+
+	_, err := config.DB.Exec(query, pq.Array(symbols), pq.Array(names), pq.Array(sectors), pq.Array(prices), pq.Array(changes), pq.Array(volumes))
+	return err
+}
+
+// GetStocks godoc
+// @Summary Get Brazilian stocks information
+// @Description Retrieve a list of all Brazilian stocks from B3 market using brapi.dev API
+// @Tags stocks
+// @Accept  json
+// @Produce  json
+// @Param search query string false "Ticker or company name (accent-insensitive, typo tolerant)"
+// @Param limit query int false "Maximum number of results" default(30)
+// @Success 200 {array} Stock
+// @Router /stocks [get]
+func GetStocks(c *gin.Context) {
+	catalog, err := loadStockCatalog(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
 
-	// Apply search
-	search := strings.ToLower(c.DefaultQuery("search", ""))
+	// Apply search (accent-insensitive, ranked, typo tolerant)
+	query := strings.TrimSpace(c.DefaultQuery("search", ""))
 	var filteredStocks []Stock
-	if search != "" {
-		for _, stock := range allStocks {
-			if strings.Contains(strings.ToLower(stock.Symbol), search) || strings.Contains(strings.ToLower(stock.Name), search) {
-				filteredStocks = append(filteredStocks, stock)
-			}
+	if query != "" {
+		for _, pos := range catalog.Index.Search(query, 0) {
+			filteredStocks = append(filteredStocks, catalog.Stocks[pos])
 		}
 	} else {
-		filteredStocks = allStocks
+		filteredStocks = catalog.Stocks
 	}
 
 	// Apply limit
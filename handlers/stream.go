@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go-br-finance-api/cache"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// Máximo de tickers por assinatura do stream
+	maxStreamTickers = 20
+	// Tempo que um ticker recusado pela brapi.dev fica fora da consulta em lote
+	streamRejectedTickerTTL = 10 * time.Minute
+)
+
+type QuoteUpdate struct {
+	Symbol    string    `json:"symbol"`
+	Price     float64   `json:"price"`
+	Change    float64   `json:"change"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type brapiQuoteResponse struct {
+	Results []struct {
+		Symbol                     string  `json:"symbol"`
+		RegularMarketPrice         float64 `json:"regularMarketPrice"`
+		RegularMarketChangePercent float64 `json:"regularMarketChangePercent"`
+	} `json:"results"`
+}
+
+type quoteSubscriber struct {
+	tickers map[string]bool
+	updates chan QuoteUpdate
+}
+
+// quoteHub distribui uma única consulta à brapi.dev para todos os assinantes
+type quoteHub struct {
+	mutex       sync.Mutex
+	subscribers map[*quoteSubscriber]struct{}
+	last        map[string]QuoteUpdate
+}
+
+var streamHub = &quoteHub{
+	subscribers: make(map[*quoteSubscriber]struct{}),
+	last:        make(map[string]QuoteUpdate),
+}
+
+func (h *quoteHub) subscribe(tickers []string) *quoteSubscriber {
+	sub := &quoteSubscriber{
+		tickers: make(map[string]bool, len(tickers)),
+		updates: make(chan QuoteUpdate, 2*len(tickers)),
+	}
+	for _, t := range tickers {
+		sub.tickers[t] = true
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.subscribers[sub] = struct{}{}
+
+	// Envia imediatamente a última cotação conhecida
+	for _, t := range tickers {
+		if update, ok := h.last[t]; ok {
+			sub.updates <- update
+		}
+	}
+
+	return sub
+}
+
+func (h *quoteHub) unsubscribe(sub *quoteSubscriber) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	delete(h.subscribers, sub)
+}
+
+// tickers retorna a união dos tickers assinados
+func (h *quoteHub) tickers() []string {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	set := make(map[string]bool)
+	for sub := range h.subscribers {
+		for t := range sub.tickers {
+			set[t] = true
+		}
+	}
+
+	tickers := make([]string, 0, len(set))
+	for t := range set {
+		tickers = append(tickers, t)
+	}
+	sort.Strings(tickers)
+	return tickers
+}
+
+// publish repassa cotações alteradas aos assinantes interessados
+func (h *quoteHub) publish(updates []QuoteUpdate) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for _, update := range updates {
+		if previous, ok := h.last[update.Symbol]; ok && previous.Price == update.Price {
+			continue
+		}
+		h.last[update.Symbol] = update
+
+		for sub := range h.subscribers {
+			if !sub.tickers[update.Symbol] {
+				continue
+			}
+			select {
+			case sub.updates <- update:
+			default:
+				// Cliente lento, descarta a atualização
+			}
+		}
+	}
+}
+
+// fetchQuotes busca as cotações de vários tickers em uma única chamada
+func fetchQuotes(tickers []string) ([]QuoteUpdate, error) {
+	url := fmt.Sprintf("https://brapi.dev/api/quote/%s?token=%s", strings.Join(tickers, ","), os.Getenv("BRAPI_TOKEN"))
+
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("brapi.dev retornou status %d", resp.StatusCode)
+	}
+
+	var brapiResp brapiQuoteResponse
+	if err := json.NewDecoder(resp.Body).Decode(&brapiResp); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	updates := make([]QuoteUpdate, 0, len(brapiResp.Results))
+	for _, r := range brapiResp.Results {
+		updates = append(updates, QuoteUpdate{
+			Symbol:    r.Symbol,
+			Price:     r.RegularMarketPrice,
+			Change:    r.RegularMarketChangePercent,
+			UpdatedAt: now,
+		})
+	}
+	return updates, nil
+}
+
+// pollQuotes consulta os tickers em lote. Se o lote falhar, consulta um a um, para que um
+// ticker desconhecido não interrompa as cotações dos demais; os recusados ficam fora das
+// consultas seguintes por streamRejectedTickerTTL
+func pollQuotes(tickers []string) []QuoteUpdate {
+	batch := make([]string, 0, len(tickers))
+	for _, t := range tickers {
+		if _, rejected := cache.GlobalCache.Get("stream:recusado:" + t); !rejected {
+			batch = append(batch, t)
+		}
+	}
+	if len(batch) == 0 {
+		return nil
+	}
+
+	updates, err := fetchQuotes(batch)
+	if err == nil {
+		return updates
+	}
+	if len(batch) == 1 {
+		log.Printf("⚠️  Erro ao atualizar cotação de %s no stream: %v", batch[0], err)
+		cache.GlobalCache.Set("stream:recusado:"+batch[0], true, streamRejectedTickerTTL)
+		return nil
+	}
+
+	log.Println("⚠️  Erro ao atualizar cotações do stream em lote, consultando um a um:", err)
+	updates = nil
+	for _, t := range batch {
+		quote, err := fetchQuotes([]string{t})
+		if err != nil {
+			log.Printf("⚠️  Erro ao atualizar cotação de %s no stream: %v", t, err)
+			cache.GlobalCache.Set("stream:recusado:"+t, true, streamRejectedTickerTTL)
+			continue
+		}
+		updates = append(updates, quote...)
+	}
+	return updates
+}
+
+// StartQuoteStreamer consulta periodicamente as cotações assinadas e distribui as atualizações
+func StartQuoteStreamer(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			tickers := streamHub.tickers()
+			if len(tickers) == 0 {
+				continue
+			}
+
+			streamHub.publish(pollQuotes(tickers))
+		}
+	}()
+}
+
+// StreamQuotes godoc
+// @Summary Stream quotes in real time
+// @Description Subscribe to a set of tickers and receive price updates via Server-Sent Events. A single server-side poller serves all subscribers
+// @Tags stocks
+// @Produce  text/event-stream
+// @Param tickers query string true "Comma-separated tickers (max 20), e.g. PETR4,VALE3"
+// @Success 200 {string} string "Stream of quote events"
+// @Failure 400 {object} map[string]string
+// @Router /stocks/stream [get]
+func StreamQuotes(c *gin.Context) {
+	var tickers []string
+	seen := make(map[string]bool)
+	for _, t := range strings.Split(c.Query("tickers"), ",") {
+		t = strings.ToUpper(strings.TrimSpace(t))
+		if t == "" {
+			continue
+		}
+		if !validTicker(t) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid ticker: %q", t)})
+			return
+		}
+		if !seen[t] {
+			seen[t] = true
+			tickers = append(tickers, t)
+		}
+	}
+
+	if len(tickers) == 0 || len(tickers) > maxStreamTickers {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("tickers must contain between 1 and %d symbols", maxStreamTickers)})
+		return
+	}
+
+	sub := streamHub.subscribe(tickers)
+	defer streamHub.unsubscribe(sub)
+
+	// Set up SSE
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(30 * time.Second)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case update := <-sub.updates:
+			data, _ := json.Marshal(update)
+			c.Writer.WriteString(fmt.Sprintf("event: quote\ndata: %s\n\n", data))
+			c.Writer.Flush()
+		case <-heartbeat.C:
+			c.Writer.WriteString(": keep-alive\n\n")
+			c.Writer.Flush()
+		}
+	}
+}
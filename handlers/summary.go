@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"go-br-finance-api/br"
+	"go-br-finance-api/config"
+	"go-br-finance-api/models"
+	"go-br-finance-api/notify"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// Horário de geração do resumo (São Paulo), após o fechamento da B3
+	resumoHora   = 18
+	resumoMinuto = 30
+	// Quantidade de ativos em cada lista de destaques do resumo
+	resumoMovers = 5
+)
+
+type IbovespaResumo struct {
+	Pontos   float64 `json:"pontos"`
+	Variacao float64 `json:"variacao"`
+}
+
+type DailySummary struct {
+	Data           string                `json:"data"`
+	Ibovespa       *IbovespaResumo       `json:"ibovespa,omitempty"`
+	Maiores        []Stock               `json:"maiores_altas"`
+	Menores        []Stock               `json:"maiores_baixas"`
+	MaisNegociadas []Stock               `json:"mais_negociadas"`
+	DolarBRL       *float64              `json:"dolar_brl,omitempty"`
+	Taxas          []Taxa                `json:"taxas"`
+	Recomendacoes  []models.Recomendacao `json:"recomendacoes"`
+	Narrativa      string                `json:"narrativa,omitempty"`
+	GeradoEm       time.Time             `json:"gerado_em"`
+}
+
+// buildDailySummary reúne os números do dia; fontes indisponíveis ficam de fora do resumo
+func buildDailySummary(ctx context.Context, data string) DailySummary {
+	summary := DailySummary{
+		Data:           data,
+		Maiores:        []Stock{},
+		Menores:        []Stock{},
+		MaisNegociadas: []Stock{},
+		Taxas:          []Taxa{},
+		Recomendacoes:  []models.Recomendacao{},
+		GeradoEm:       time.Now(),
+	}
+
+	if quotes, err := fetchQuotes([]string{ibovespaSymbol}); err == nil && len(quotes) > 0 {
+		summary.Ibovespa = &IbovespaResumo{Pontos: quotes[0].Price, Variacao: quotes[0].Change}
+	} else if err != nil {
+		log.Println("⚠️  Resumo diário sem Ibovespa:", err)
+	}
+
+	if stocks, err := loadStocks(ctx); err == nil {
+		movers := computeMovers(stocks, resumoMovers)
+		summary.Maiores, summary.Menores, summary.MaisNegociadas = movers.Gainers, movers.Losers, movers.MostTraded
+	} else {
+		log.Println("⚠️  Resumo diário sem destaques:", err)
+	}
+
+	if rate, err := fetchExchangeRate("USD"); err == nil {
+		summary.DolarBRL = &rate
+	} else {
+		log.Println("⚠️  Resumo diário sem câmbio:", err)
+	}
+
+	if taxas, err := fetchTaxas(); err == nil {
+		for _, taxa := range taxas {
+			if nome := strings.ToUpper(taxa.Nome); nome == "SELIC" || nome == "CDI" {
+				summary.Taxas = append(summary.Taxas, taxa)
+			}
+		}
+	} else {
+		log.Println("⚠️  Resumo diário sem taxas:", err)
+	}
+
+	err := config.DB.Select(&summary.Recomendacoes, "SELECT * FROM recomendacoes_financeiras WHERE created_at::date = $1 ORDER BY id", data)
+	if err != nil {
+		log.Println("⚠️  Resumo diário sem recomendações:", err)
+	}
+
+	if os.Getenv("SUMMARY_NARRATIVE") == "true" {
+		narrativa, err := summaryNarrative(summary)
+		if err != nil {
+			log.Println("⚠️  Resumo diário sem narrativa:", err)
+		}
+		summary.Narrativa = narrativa
+	}
+
+	return summary
+}
+
+// summaryNarrative pede ao modelo do chat um parágrafo curto baseado apenas nos números do resumo
+func summaryNarrative(summary DailySummary) (string, error) {
+	numeros, err := json.Marshal(summary)
+	if err != nil {
+		return "", err
+	}
+
+	return ollamaComplete([]OllamaMessage{
+		{
+			Role:    "system",
+			Content: "Você é um analista de mercado brasileiro. Escreva em português brasileiro um parágrafo curto (no máximo 600 caracteres) resumindo o pregão, usando apenas os números fornecidos, sem recomendar compra ou venda.",
+		},
+		{Role: "user", Content: string(numeros)},
+	})
+}
+
+// generateDailySummary monta e grava o resumo da data, substituindo um existente
+func generateDailySummary(ctx context.Context, data string) (DailySummary, error) {
+	summary := buildDailySummary(ctx, data)
+
+	payload, err := json.Marshal(summary)
+	if err != nil {
+		return summary, err
+	}
+
+	query := `INSERT INTO resumos_diarios (data, payload) VALUES ($1, $2)
+		ON CONFLICT (data) DO UPDATE SET payload = EXCLUDED.payload, created_at = CURRENT_TIMESTAMP`
+	if _, err := config.DB.Exec(query, data, payload); err != nil {
+		return summary, err
+	}
+	return summary, nil
+}
+
+// nextSummaryRun retorna o próximo horário de geração, em dias de pregão da B3
+func nextSummaryRun(now time.Time) time.Time {
+	now = now.In(saoPauloLocation())
+	next := time.Date(now.Year(), now.Month(), now.Day(), resumoHora, resumoMinuto, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	for !br.DiaDePregao(next) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// claimDailySummary reserva a geração da data; com várias réplicas só a primeira gera e notifica
+func claimDailySummary(data string) (bool, error) {
+	result, err := config.DB.Exec("INSERT INTO resumos_execucoes (data) VALUES ($1) ON CONFLICT DO NOTHING", data)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected == 1, nil
+}
+
+// runDailySummary gera o resumo da data e notifica os assinantes, se nenhuma outra réplica já o fez
+func runDailySummary(data string) {
+	claimed, err := claimDailySummary(data)
+	if err != nil {
+		log.Println("⚠️  Erro ao reservar resumo diário:", err)
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	summary, err := generateDailySummary(context.Background(), data)
+	if err != nil {
+		log.Println("⚠️  Erro ao gravar resumo diário:", err)
+		// Libera a data para uma nova tentativa
+		config.DB.Exec("DELETE FROM resumos_execucoes WHERE data = $1", data)
+		return
+	}
+
+	body := "Confira o resumo do mercado de hoje"
+	if summary.Ibovespa != nil {
+		body = fmt.Sprintf("Ibovespa %+.2f%% aos %.0f pontos", summary.Ibovespa.Variacao, summary.Ibovespa.Pontos)
+	}
+	notifyTopic(models.TopicoResumoDiario, notify.Notification{
+		Title: "Resumo do mercado",
+		Body:  body,
+		Data:  map[string]string{"tipo": "resumo_diario", "data": data},
+	})
+}
+
+// StartDailySummaryJob gera o resumo do mercado todo dia de pregão após o fechamento e notifica os assinantes.
+// Ao iniciar depois do horário em um dia de pregão, gera o resumo do dia caso ele ainda não tenha sido gerado.
+func StartDailySummaryJob() {
+	go func() {
+		now := time.Now().In(saoPauloLocation())
+		today := time.Date(now.Year(), now.Month(), now.Day(), resumoHora, resumoMinuto, 0, 0, now.Location())
+		if br.DiaDePregao(today) && !now.Before(today) {
+			runDailySummary(today.Format(dateLayout))
+		}
+
+		for {
+			next := nextSummaryRun(time.Now())
+			time.Sleep(time.Until(next))
+			runDailySummary(next.Format(dateLayout))
+		}
+	}()
+}
+
+// GetDailySummary godoc
+// @Summary Resumo diário do mercado
+// @Description Retorna o resumo gerado após o fechamento (Ibovespa, destaques, dólar, Selic/CDI, novas recomendações e, se habilitada, uma narrativa)
+// @Tags summary
+// @Accept  json
+// @Produce  json
+// @Param date path string true "Data no formato YYYY-MM-DD"
+// @Success 200 {object} DailySummary
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /summary/{date} [get]
+func GetDailySummary(c *gin.Context) {
+	date := c.Param("date")
+	if _, err := time.Parse(dateLayout, date); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "data deve estar no formato YYYY-MM-DD"})
+		return
+	}
+
+	var payload []byte
+	err := config.DB.Get(&payload, "SELECT payload FROM resumos_diarios WHERE data = $1", date)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"erro": "Resumo não encontrado para a data"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao buscar resumo"})
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", payload)
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"net/http"
+
+	"go-br-finance-api/br"
+
+	"github.com/gin-gonic/gin"
+	"github.com/skip2/go-qrcode"
+)
+
+// Tamanho em pixels da imagem do QR Code Pix
+const pixQRCodeSize = 320
+
+type BoletoRequest struct {
+	Codigo string `json:"codigo" binding:"required"` // código de barras ou linha digitável, com ou sem pontuação
+}
+
+// ParseBoleto godoc
+// @Summary Validar e interpretar boleto
+// @Description Valida boletos bancários e de arrecadação (contas de consumo, tributos) a partir do código de barras (44 dígitos) ou da linha digitável (47 ou 48 dígitos), conferindo todos os dígitos verificadores. Retorna banco, valor, vencimento (fator de vencimento, incluindo o novo ciclo iniciado em 22/02/2025) e segmento
+// @Tags tools
+// @Accept  json
+// @Produce  json
+// @Param request body BoletoRequest true "Código do boleto"
+// @Success 200 {object} br.Boleto
+// @Failure 400 {object} map[string]string
+// @Router /tools/boleto/parse [post]
+func ParseBoleto(c *gin.Context) {
+	var req BoletoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Dados inválidos", "detalhes": err.Error()})
+		return
+	}
+
+	boleto, err := br.ParseBoleto(req.Codigo, today())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, boleto)
+}
+
+type PixDecodeRequest struct {
+	Payload string `json:"payload" binding:"required"` // "Pix copia e cola"
+}
+
+type PixGenerateResponse struct {
+	Payload string `json:"payload"`
+	QRCode  string `json:"qr_code"` // imagem PNG em data URI
+}
+
+// GeneratePix godoc
+// @Summary Gerar Pix copia e cola
+// @Description Gera o payload BR Code de um Pix estático (chave, valor opcional, nome e cidade do recebedor, txid e descrição) e o QR Code correspondente. Use format=png para receber apenas a imagem
+// @Tags tools
+// @Accept  json
+// @Produce  json
+// @Produce  png
+// @Param request body br.Pix true "Dados do Pix"
+// @Param format query string false "json (padrão) ou png"
+// @Success 200 {object} PixGenerateResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tools/pix/generate [post]
+func GeneratePix(c *gin.Context) {
+	var req br.Pix
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Dados inválidos", "detalhes": err.Error()})
+		return
+	}
+
+	payload, err := br.GeneratePix(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": err.Error()})
+		return
+	}
+
+	png, err := qrcode.Encode(payload, qrcode.Medium, pixQRCodeSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao gerar QR Code"})
+		return
+	}
+
+	if c.Query("format") == "png" {
+		c.Data(http.StatusOK, "image/png", png)
+		return
+	}
+
+	c.JSON(http.StatusOK, PixGenerateResponse{
+		Payload: payload,
+		QRCode:  "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+	})
+}
+
+// DecodePix godoc
+// @Summary Decodificar Pix copia e cola
+// @Description Interpreta um payload BR Code (estático ou dinâmico) e valida sua estrutura e CRC16
+// @Tags tools
+// @Accept  json
+// @Produce  json
+// @Param request body PixDecodeRequest true "Payload do Pix"
+// @Success 200 {object} br.Pix
+// @Failure 400 {object} map[string]string
+// @Router /tools/pix/decode [post]
+func DecodePix(c *gin.Context) {
+	var req PixDecodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Dados inválidos", "detalhes": err.Error()})
+		return
+	}
+
+	pix, err := br.DecodePix(req.Payload)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, pix)
+}
+
+type ValidateDocumentRequest struct {
+	Numero string `json:"numero" binding:"required"` // com ou sem pontuação
+}
+
+// ValidateDocument godoc
+// @Summary Validar CPF ou CNPJ
+// @Description Confere os dígitos verificadores de um CPF ou CNPJ (inclusive o CNPJ alfanumérico adotado a partir de 2026) e retorna o número sem pontuação e formatado
+// @Tags tools
+// @Accept  json
+// @Produce  json
+// @Param tipo path string true "cpf ou cnpj"
+// @Param request body ValidateDocumentRequest true "Número do documento"
+// @Success 200 {object} br.Documento
+// @Failure 400 {object} map[string]string
+// @Router /tools/validate/{tipo} [post]
+func ValidateDocument(c *gin.Context) {
+	var req ValidateDocumentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Dados inválidos", "detalhes": err.Error()})
+		return
+	}
+
+	switch c.Param("tipo") {
+	case br.DocumentoCPF:
+		c.JSON(http.StatusOK, br.ValidateCPF(req.Numero))
+	case br.DocumentoCNPJ:
+		c.JSON(http.StatusOK, br.ValidateCNPJ(req.Numero))
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "tipo deve ser cpf ou cnpj"})
+	}
+}
@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// currentUserID identifica o usuário pelo cabeçalho X-User-ID. O valor não é autenticado:
+// só separa os dados por usuário quando vem de um gateway confiável.
+// Responde 401 e retorna false quando o cabeçalho não é enviado.
+func currentUserID(c *gin.Context) (string, bool) {
+	return requireUserID(c, gin.H{"erro": "Cabeçalho X-User-ID é obrigatório"})
+}
+
+// currentChatUserID faz o mesmo para as rotas do chat, que respondem erros em inglês
+func currentChatUserID(c *gin.Context) (string, bool) {
+	return requireUserID(c, gin.H{"error": "X-User-ID header is required"})
+}
+
+func requireUserID(c *gin.Context, missing gin.H) (string, bool) {
+	userID := strings.TrimSpace(c.GetHeader("X-User-ID"))
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, missing)
+		return "", false
+	}
+	return userID, true
+}
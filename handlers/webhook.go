@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+var errWebhookDestino = errors.New("destino do webhook não permitido")
+
+// Faixa de CGNAT (RFC 6598), também interna para o servidor
+var cgnatRange = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// publicIP diz se o IP pode receber webhooks: recusa loopback, redes privadas, link-local
+// (incluindo o endpoint de metadados 169.254.169.254), multicast e endereço não especificado
+func publicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() || cgnatRange.Contains(ip))
+}
+
+// validateWebhookURL exige uma URL http(s) cujo host resolva apenas para IPs públicos
+func validateWebhookURL(ctx context.Context, rawURL string) error {
+	webhook, err := url.Parse(rawURL)
+	if err != nil || (webhook.Scheme != "http" && webhook.Scheme != "https") || webhook.Hostname() == "" {
+		return errors.New("webhook_url deve ser uma URL http(s) válida")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	ips, err := net.DefaultResolver.LookupIPAddr(ctx, webhook.Hostname())
+	if err != nil || len(ips) == 0 {
+		return fmt.Errorf("não foi possível resolver o host %s", webhook.Hostname())
+	}
+	for _, ip := range ips {
+		if !publicIP(ip.IP) {
+			return fmt.Errorf("%w: %s resolve para um endereço interno", errWebhookDestino, webhook.Hostname())
+		}
+	}
+	return nil
+}
+
+// webhookDialer confere o IP no momento da conexão, depois da resolução de DNS,
+// para que um host validado não passe a apontar para um endereço interno (DNS rebinding)
+var webhookDialer = &net.Dialer{
+	Timeout: 5 * time.Second,
+	Control: func(network, address string, _ syscall.RawConn) error {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return err
+		}
+		if ip := net.ParseIP(host); ip == nil || !publicIP(ip) {
+			return fmt.Errorf("%w: %s", errWebhookDestino, host)
+		}
+		return nil
+	},
+}
+
+// webhookClient não usa proxy nem segue redirecionamentos
+var webhookClient = &http.Client{
+	Timeout: 10 * time.Second,
+	Transport: &http.Transport{
+		DialContext:         webhookDialer.DialContext,
+		TLSHandshakeTimeout: 5 * time.Second,
+	},
+	CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
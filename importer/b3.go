@@ -0,0 +1,195 @@
+package importer
+
+import (
+	"fmt"
+	"strings"
+
+	"go-br-finance-api/search"
+)
+
+// Negociacao é uma compra ou venda do relatório de negociação da Área do Investidor da B3
+type Negociacao struct {
+	Data        string  `json:"data"` // YYYY-MM-DD
+	Operacao    string  `json:"operacao"`
+	Mercado     string  `json:"mercado"`
+	Instituicao string  `json:"instituicao"`
+	Codigo      string  `json:"codigo"`
+	Quantidade  float64 `json:"quantidade"`
+	Preco       float64 `json:"preco"`
+	Valor       float64 `json:"valor"`
+}
+
+// Provento é um dividendo, JCP ou rendimento do relatório de movimentação da B3
+type Provento struct {
+	Data        string  `json:"data"` // YYYY-MM-DD
+	Tipo        string  `json:"tipo"` // dividendo, jcp ou rendimento
+	Codigo      string  `json:"codigo"`
+	Produto     string  `json:"produto"`
+	Instituicao string  `json:"instituicao"`
+	Quantidade  float64 `json:"quantidade"`
+	Valor       float64 `json:"valor"`
+}
+
+type B3Import struct {
+	Relatorio   string       `json:"relatorio"` // negociacao ou movimentacao
+	Negociacoes []Negociacao `json:"negociacoes"`
+	Proventos   []Provento   `json:"proventos"`
+	Ignoradas   int          `json:"ignoradas"`
+}
+
+// Tipos de movimentação da B3 importados como proventos
+var movimentacaoProventos = map[string]string{
+	"dividendo":                               "dividendo",
+	"juros sobre capital proprio":             "jcp",
+	"rendimento":                              "rendimento",
+	"dividendo transferido":                   "dividendo",
+	"juros sobre capital proprio transferido": "jcp",
+	"rendimento transferido":                  "rendimento",
+}
+
+// ParseB3 reconhece o relatório (negociação ou movimentação) pelo cabeçalho e extrai os lançamentos
+func ParseB3(rows [][]string) (*B3Import, error) {
+	for i, row := range rows {
+		header := headerIndex(row)
+		if _, ok := header["data do negocio"]; ok {
+			return parseNegociacao(header, rows[i+1:])
+		}
+		if _, ok := header["movimentacao"]; ok {
+			if _, ok := header["entrada saida"]; ok {
+				return parseMovimentacao(header, rows[i+1:])
+			}
+		}
+	}
+	return nil, fmt.Errorf("formato não reconhecido: esperado relatório de negociação ou movimentação da B3")
+}
+
+func headerIndex(row []string) map[string]int {
+	header := make(map[string]int, len(row))
+	for i, col := range row {
+		header[search.Fold(col)] = i
+	}
+	return header
+}
+
+func cell(row []string, header map[string]int, name string) string {
+	i, ok := header[name]
+	if !ok || i >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[i])
+}
+
+func parseNegociacao(header map[string]int, rows [][]string) (*B3Import, error) {
+	result := &B3Import{Relatorio: "negociacao", Negociacoes: []Negociacao{}, Proventos: []Provento{}}
+
+	for _, row := range rows {
+		if isEmptyRow(row) {
+			continue
+		}
+
+		date, err := ParseDate(cell(row, header, "data do negocio"))
+		if err != nil {
+			result.Ignoradas++
+			continue
+		}
+
+		var operacao string
+		switch search.Fold(cell(row, header, "tipo de movimentacao")) {
+		case "compra":
+			operacao = "compra"
+		case "venda":
+			operacao = "venda"
+		default:
+			result.Ignoradas++
+			continue
+		}
+
+		quantidade, errQtd := ParseNumber(cell(row, header, "quantidade"))
+		preco, errPreco := ParseNumber(cell(row, header, "preco"))
+		valor, _ := ParseNumber(cell(row, header, "valor"))
+		codigo := normalizeTicker(cell(row, header, "codigo de negociacao"))
+		if errQtd != nil || errPreco != nil || quantidade <= 0 || codigo == "" {
+			result.Ignoradas++
+			continue
+		}
+
+		result.Negociacoes = append(result.Negociacoes, Negociacao{
+			Data:        date.Format("2006-01-02"),
+			Operacao:    operacao,
+			Mercado:     cell(row, header, "mercado"),
+			Instituicao: cell(row, header, "instituicao"),
+			Codigo:      codigo,
+			Quantidade:  quantidade,
+			Preco:       preco,
+			Valor:       valor,
+		})
+	}
+
+	return result, nil
+}
+
+func parseMovimentacao(header map[string]int, rows [][]string) (*B3Import, error) {
+	result := &B3Import{Relatorio: "movimentacao", Negociacoes: []Negociacao{}, Proventos: []Provento{}}
+
+	for _, row := range rows {
+		if isEmptyRow(row) {
+			continue
+		}
+
+		// Compras e vendas vêm do relatório de negociação; aqui só entram créditos de proventos
+		tipo := movimentacaoProventos[search.Fold(cell(row, header, "movimentacao"))]
+		if tipo == "" || search.Fold(cell(row, header, "entrada saida")) != "credito" {
+			result.Ignoradas++
+			continue
+		}
+
+		date, err := ParseDate(cell(row, header, "data"))
+		if err != nil {
+			result.Ignoradas++
+			continue
+		}
+
+		valor, err := ParseNumber(cell(row, header, "valor da operacao"))
+		if err != nil || valor <= 0 {
+			result.Ignoradas++
+			continue
+		}
+		quantidade, _ := ParseNumber(cell(row, header, "quantidade"))
+
+		produto := cell(row, header, "produto")
+		codigo := produto
+		if i := strings.Index(produto, " - "); i >= 0 {
+			codigo = produto[:i]
+		}
+
+		result.Proventos = append(result.Proventos, Provento{
+			Data:        date.Format("2006-01-02"),
+			Tipo:        tipo,
+			Codigo:      normalizeTicker(codigo),
+			Produto:     produto,
+			Instituicao: cell(row, header, "instituicao"),
+			Quantidade:  quantidade,
+			Valor:       valor,
+		})
+	}
+
+	return result, nil
+}
+
+// normalizeTicker remove o sufixo do mercado fracionário (PETR4F → PETR4)
+func normalizeTicker(codigo string) string {
+	codigo = strings.ToUpper(strings.TrimSpace(codigo))
+	if len(codigo) >= 6 && strings.HasSuffix(codigo, "F") && codigo[len(codigo)-2] >= '0' && codigo[len(codigo)-2] <= '9' {
+		codigo = codigo[:len(codigo)-1]
+	}
+	return codigo
+}
+
+func isEmptyRow(row []string) bool {
+	for _, col := range row {
+		if strings.TrimSpace(col) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,101 @@
+package importer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseB3Negociacao(t *testing.T) {
+	rows := [][]string{
+		{"Data do Negócio", "Tipo de Movimentação", "Mercado", "Prazo/Vencimento", "Instituição", "Código de Negociação", "Quantidade", "Preço", "Valor"},
+		{"15/01/2024", "Compra", "Mercado à Vista", "-", "XP INVESTIMENTOS", "PETR4", "100", "38,50", "3.850,00"},
+		{"16/01/2024", "Venda", "Mercado Fracionário", "-", "XP INVESTIMENTOS", "itsa4f", "7", "R$ 10,01", "R$ 70,07"},
+		{"", "", "", "", "", "", "", "", ""},
+		{"17/01/2024", "Compra", "Mercado à Vista", "-", "XP INVESTIMENTOS", "", "10", "1,00", "10,00"},     // sem código
+		{"17/01/2024", "Compra", "Mercado à Vista", "-", "XP INVESTIMENTOS", "VALE3", "0", "60,00", "0,00"}, // sem quantidade
+		{"17/01/2024", "Subscrição", "Mercado à Vista", "-", "XP INVESTIMENTOS", "VALE3", "1", "60,00", "60,00"},
+		{"Total", "", "", "", "", "", "", "", "3.920,07"},
+	}
+
+	got, err := ParseB3(rows)
+	if err != nil {
+		t.Fatalf("ParseB3: %v", err)
+	}
+
+	want := []Negociacao{
+		{Data: "2024-01-15", Operacao: "compra", Mercado: "Mercado à Vista", Instituicao: "XP INVESTIMENTOS", Codigo: "PETR4", Quantidade: 100, Preco: 38.5, Valor: 3850},
+		{Data: "2024-01-16", Operacao: "venda", Mercado: "Mercado Fracionário", Instituicao: "XP INVESTIMENTOS", Codigo: "ITSA4", Quantidade: 7, Preco: 10.01, Valor: 70.07},
+	}
+	if got.Relatorio != "negociacao" || got.Ignoradas != 4 || len(got.Proventos) != 0 {
+		t.Errorf("ParseB3 = relatório %q, %d ignoradas, %d proventos; esperado negociacao, 4, 0", got.Relatorio, got.Ignoradas, len(got.Proventos))
+	}
+	if !reflect.DeepEqual(got.Negociacoes, want) {
+		t.Errorf("Negociacoes = %+v, esperado %+v", got.Negociacoes, want)
+	}
+}
+
+func TestParseB3Movimentacao(t *testing.T) {
+	rows := [][]string{
+		{"Relatório de movimentação"}, // linhas antes do cabeçalho são puladas
+		{"Entrada/Saída", "Data", "Movimentação", "Produto", "Instituição", "Quantidade", "Preço unitário", "Valor da Operação"},
+		{"Credito", "20/02/2024", "Dividendo", "PETR4 - PETROLEO BRASILEIRO S/A PETROBRAS", "XP INVESTIMENTOS", "100", "0,5", "50,00"},
+		{"Credito", "21/02/2024", "Juros Sobre Capital Próprio", "ITSA4 - ITAUSA S.A.", "XP INVESTIMENTOS", "300", "0,02", "6,12"},
+		{"Credito", "22/02/2024", "Rendimento", "MXRF11 - MAXI RENDA FDO INV IMOB", "XP INVESTIMENTOS", "50", "0,1", "5"},
+		{"Credito", "23/02/2024", "Dividendo - Transferido", "VALE3 - VALE S.A.", "XP INVESTIMENTOS", "10", "2,73", "27,30"},
+		{"Debito", "23/02/2024", "Transferência - Liquidação", "VALE3 - VALE S.A.", "XP INVESTIMENTOS", "10", "60", "600"},
+		{"Credito", "24/02/2024", "Atualização", "PETR4 - PETROLEO BRASILEIRO S/A PETROBRAS", "XP INVESTIMENTOS", "100", "-", "-"},
+		{"Credito", "25/02/2024", "Dividendo", "BBAS3 - BANCO DO BRASIL S/A", "XP INVESTIMENTOS", "10", "-", "-"}, // sem valor
+	}
+
+	got, err := ParseB3(rows)
+	if err != nil {
+		t.Fatalf("ParseB3: %v", err)
+	}
+
+	want := []Provento{
+		{Data: "2024-02-20", Tipo: "dividendo", Codigo: "PETR4", Produto: "PETR4 - PETROLEO BRASILEIRO S/A PETROBRAS", Instituicao: "XP INVESTIMENTOS", Quantidade: 100, Valor: 50},
+		{Data: "2024-02-21", Tipo: "jcp", Codigo: "ITSA4", Produto: "ITSA4 - ITAUSA S.A.", Instituicao: "XP INVESTIMENTOS", Quantidade: 300, Valor: 6.12},
+		{Data: "2024-02-22", Tipo: "rendimento", Codigo: "MXRF11", Produto: "MXRF11 - MAXI RENDA FDO INV IMOB", Instituicao: "XP INVESTIMENTOS", Quantidade: 50, Valor: 5},
+		{Data: "2024-02-23", Tipo: "dividendo", Codigo: "VALE3", Produto: "VALE3 - VALE S.A.", Instituicao: "XP INVESTIMENTOS", Quantidade: 10, Valor: 27.3},
+	}
+	if got.Relatorio != "movimentacao" || got.Ignoradas != 3 || len(got.Negociacoes) != 0 {
+		t.Errorf("ParseB3 = relatório %q, %d ignoradas, %d negociações; esperado movimentacao, 3, 0", got.Relatorio, got.Ignoradas, len(got.Negociacoes))
+	}
+	if !reflect.DeepEqual(got.Proventos, want) {
+		t.Errorf("Proventos = %+v, esperado %+v", got.Proventos, want)
+	}
+}
+
+func TestParseB3FormatoDesconhecido(t *testing.T) {
+	tests := []struct {
+		name string
+		rows [][]string
+	}{
+		{name: "vazio"},
+		{name: "extrato bancário", rows: [][]string{{"Data", "Descrição", "Valor"}, {"01/02/2024", "Padaria", "-12,50"}}},
+		{name: "movimentação sem entrada/saída", rows: [][]string{{"Data", "Movimentação", "Produto"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := ParseB3(tt.rows); err == nil {
+				t.Fatalf("ParseB3 = %+v, esperado erro", got)
+			}
+		})
+	}
+}
+
+func TestNormalizeTicker(t *testing.T) {
+	tests := map[string]string{
+		"PETR4F":  "PETR4",
+		" itsa4f": "ITSA4",
+		"TAEE11F": "TAEE11",
+		"MXRF11":  "MXRF11",
+		"VALE3":   "VALE3",
+		"ABCF":    "ABCF",
+	}
+	for in, want := range tests {
+		if got := normalizeTicker(in); got != want {
+			t.Errorf("normalizeTicker(%q) = %q, esperado %q", in, got, want)
+		}
+	}
+}
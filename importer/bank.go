@@ -0,0 +1,71 @@
+package importer
+
+import (
+	"fmt"
+)
+
+// Nomes de coluna (normalizados) usados pelos extratos CSV dos bancos mais comuns
+var (
+	bankDateColumns        = []string{"data", "data lancamento", "data da transacao", "data movimento", "date"}
+	bankDescriptionColumns = []string{"descricao", "historico", "lancamento", "estabelecimento", "title", "description"}
+	bankAmountColumns      = []string{"valor", "valor r", "valor rs", "amount"}
+	bankIDColumns          = []string{"identificador", "id", "documento", "nr documento"}
+)
+
+func findColumn(header map[string]int, names []string) (string, bool) {
+	for _, name := range names {
+		if _, ok := header[name]; ok {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// ParseBankCSV extrai os lançamentos de extratos CSV (Nubank, Inter, Itaú, Bradesco e similares),
+// localizando a linha de cabeçalho com data, descrição e valor
+func ParseBankCSV(rows [][]string) ([]ExtratoLancamento, error) {
+	for i, row := range rows {
+		header := headerIndex(row)
+		dateCol, okDate := findColumn(header, bankDateColumns)
+		amountCol, okAmount := findColumn(header, bankAmountColumns)
+		if !okDate || !okAmount {
+			continue
+		}
+		descCol, _ := findColumn(header, bankDescriptionColumns)
+		idCol, _ := findColumn(header, bankIDColumns)
+
+		// Fatura do cartão (ex: Nubank "date,title,amount") lista compras com valor positivo
+		fatura := dateCol == "date" && descCol == "title"
+
+		lancamentos := []ExtratoLancamento{}
+		for j, r := range rows[i+1:] {
+			if isEmptyRow(r) {
+				continue
+			}
+			data, err := ParseDate(cell(r, header, dateCol))
+			if err != nil {
+				// Linhas de saldo e totais não têm data
+				continue
+			}
+			valor, err := ParseNumber(cell(r, header, amountCol))
+			if err != nil {
+				return nil, fmt.Errorf("valor inválido na linha %d: %q", i+j+2, cell(r, header, amountCol))
+			}
+			if fatura {
+				valor = -valor
+			}
+
+			lancamento := ExtratoLancamento{Data: data.Format("2006-01-02"), Valor: valor}
+			if idCol != "" {
+				lancamento.ID = cell(r, header, idCol)
+			}
+			if descCol != "" {
+				lancamento.Descricao = cell(r, header, descCol)
+			}
+			lancamentos = append(lancamentos, lancamento)
+		}
+		return lancamentos, nil
+	}
+
+	return nil, fmt.Errorf("formato não reconhecido: esperado cabeçalho com data, descrição e valor")
+}
@@ -0,0 +1,91 @@
+package importer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseBankCSV(t *testing.T) {
+	tests := []struct {
+		name string
+		rows [][]string
+		want []ExtratoLancamento
+	}{
+		{
+			name: "fatura do Nubank inverte o sinal",
+			rows: [][]string{
+				{"date", "title", "amount"},
+				{"2024-01-05", "Padaria Centro", "12.50"},
+				{"2024-01-07", "Pagamento recebido", "-300.00"},
+			},
+			want: []ExtratoLancamento{
+				{Data: "2024-01-05", Descricao: "Padaria Centro", Valor: -12.5},
+				{Data: "2024-01-07", Descricao: "Pagamento recebido", Valor: 300},
+			},
+		},
+		{
+			name: "conta do Nubank com identificador",
+			rows: [][]string{
+				{"Data", "Valor", "Identificador", "Descrição"},
+				{"05/01/2024", "-45.90", "65a1b2c3", "Compra no débito - Mercado"},
+			},
+			want: []ExtratoLancamento{
+				{ID: "65a1b2c3", Data: "2024-01-05", Descricao: "Compra no débito - Mercado", Valor: -45.9},
+			},
+		},
+		{
+			name: "extrato com linhas de título e saldo",
+			rows: [][]string{
+				{"Extrato Conta Corrente"},
+				{"Agência: 1234 Conta: 56789-0"},
+				{},
+				{"Data Lançamento", "Histórico", "Valor (R$)", "Saldo (R$)"},
+				{"", "SALDO ANTERIOR", "", "1.000,00"},
+				{"10/01/2024", "PIX ENVIADO FULANO", "-1.234,56", "-234,56"},
+				{"11/01/2024", "TED RECEBIDA", "2.000,00", "1.765,44"},
+				{"", "", "", ""},
+				{"Total", "", "765,44", ""},
+			},
+			want: []ExtratoLancamento{
+				{Data: "2024-01-10", Descricao: "PIX ENVIADO FULANO", Valor: -1234.56},
+				{Data: "2024-01-11", Descricao: "TED RECEBIDA", Valor: 2000},
+			},
+		},
+		{
+			name: "sem lançamentos",
+			rows: [][]string{{"Data", "Descrição", "Valor"}},
+			want: []ExtratoLancamento{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseBankCSV(tt.rows)
+			if err != nil {
+				t.Fatalf("ParseBankCSV: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseBankCSV = %+v, esperado %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseBankCSVInvalido(t *testing.T) {
+	tests := []struct {
+		name string
+		rows [][]string
+	}{
+		{name: "vazio"},
+		{name: "sem coluna de valor", rows: [][]string{{"Data", "Descrição"}, {"01/02/2024", "Padaria"}}},
+		{name: "relatório da B3", rows: [][]string{{"Data do Negócio", "Tipo de Movimentação", "Código de Negociação"}}},
+		{name: "valor inválido", rows: [][]string{{"Data", "Descrição", "Valor"}, {"01/02/2024", "Padaria", "doze"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := ParseBankCSV(tt.rows); err == nil {
+				t.Fatalf("ParseBankCSV = %+v, esperado erro", got)
+			}
+		})
+	}
+}
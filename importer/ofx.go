@@ -0,0 +1,121 @@
+package importer
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ExtratoLancamento é uma movimentação de extrato bancário (OFX ou CSV)
+type ExtratoLancamento struct {
+	ID        string  `json:"id,omitempty"`    // FITID do OFX ou identificador do CSV, quando houver
+	Conta     string  `json:"conta,omitempty"` // BANKID/ACCTID do OFX; o FITID só é único dentro da conta
+	Data      string  `json:"data"`            // YYYY-MM-DD
+	Descricao string  `json:"descricao"`
+	Valor     float64 `json:"valor"` // negativo para débitos
+}
+
+var (
+	ofxTransaction = regexp.MustCompile(`(?is)<STMTTRN>(.*?)</STMTTRN>`)
+	// Em OFX 1.x (SGML) as tags folha não são fechadas; o valor vai até a próxima tag ou fim de linha
+	ofxField = regexp.MustCompile(`(?i)<([A-Z0-9.]+)>([^<\r\n]*)`)
+	// Identificação da conta (BANKACCTFROM ou CCACCTFROM), que precede as transações de cada extrato
+	ofxAccount = regexp.MustCompile(`(?i)<(BANKID|ACCTID)>([^<\r\n]*)`)
+)
+
+// IsOFX indica se o conteúdo parece um arquivo OFX
+func IsOFX(data []byte) bool {
+	head := data
+	if len(head) > 2048 {
+		head = head[:2048]
+	}
+	return bytes.Contains(bytes.ToUpper(head), []byte("<OFX>")) || bytes.Contains(head, []byte("OFXHEADER"))
+}
+
+// ParseOFX extrai as transações (STMTTRN) de extratos de conta ou cartão em OFX 1.x ou 2.x
+func ParseOFX(data []byte) ([]ExtratoLancamento, error) {
+	if !utf8.Valid(data) {
+		// Bancos brasileiros geralmente exportam com CHARSET:1252
+		data = latin1ToUTF8(data)
+	}
+
+	matches := ofxTransaction.FindAllSubmatchIndex(data, -1)
+	if len(matches) == 0 {
+		if !IsOFX(data) {
+			return nil, fmt.Errorf("arquivo não é um OFX válido")
+		}
+		return []ExtratoLancamento{}, nil
+	}
+
+	accounts := ofxAccount.FindAllSubmatchIndex(data, -1)
+
+	lancamentos := make([]ExtratoLancamento, 0, len(matches))
+	for _, m := range matches {
+		fields := make(map[string]string)
+		for _, f := range ofxField.FindAllSubmatch(data[m[2]:m[3]], -1) {
+			fields[strings.ToUpper(string(f[1]))] = strings.TrimSpace(string(f[2]))
+		}
+
+		// DTPOSTED vem como AAAAMMDD[HHMMSS[.XXX]][TZ]
+		posted := fields["DTPOSTED"]
+		if len(posted) < 8 {
+			return nil, fmt.Errorf("transação %q sem DTPOSTED válido", fields["FITID"])
+		}
+		date, err := time.Parse("20060102", posted[:8])
+		if err != nil {
+			return nil, fmt.Errorf("transação %q: DTPOSTED inválido %q", fields["FITID"], posted)
+		}
+
+		valor, err := ParseNumber(fields["TRNAMT"])
+		if err != nil {
+			return nil, fmt.Errorf("transação %q: valor inválido %q", fields["FITID"], fields["TRNAMT"])
+		}
+
+		descricao := fields["MEMO"]
+		if name := fields["NAME"]; name != "" && !strings.Contains(descricao, name) {
+			descricao = strings.TrimSpace(name + " " + descricao)
+		}
+
+		lancamentos = append(lancamentos, ExtratoLancamento{
+			ID:        fields["FITID"],
+			Conta:     ofxAccountAt(data, accounts, m[0]),
+			Data:      date.Format("2006-01-02"),
+			Descricao: descricao,
+			Valor:     valor,
+		})
+	}
+
+	return lancamentos, nil
+}
+
+// ofxAccountAt monta "BANKID/ACCTID" com os últimos identificadores de conta antes da posição,
+// já que um OFX pode trazer extratos de várias contas
+func ofxAccountAt(data []byte, accounts [][]int, pos int) string {
+	var bankID, acctID string
+	for _, a := range accounts {
+		if a[0] > pos {
+			break
+		}
+		value := strings.TrimSpace(string(data[a[4]:a[5]]))
+		if strings.EqualFold(string(data[a[2]:a[3]]), "BANKID") {
+			bankID, acctID = value, ""
+			continue
+		}
+		if acctID != "" {
+			// Novo ACCTID sem BANKID antes: conta de outro extrato, ex. cartão
+			bankID = ""
+		}
+		acctID = value
+	}
+	if acctID == "" {
+		return ""
+	}
+	if bankID == "" {
+		// Extratos de cartão (CCACCTFROM) não têm BANKID
+		return acctID
+	}
+	return bankID + "/" + acctID
+}
@@ -0,0 +1,139 @@
+package importer
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Extrato em OFX 1.x (SGML), com conta corrente e cartão no mesmo arquivo e tags folha sem fechamento
+const ofxSGML = `OFXHEADER:100
+DATA:OFXSGML
+VERSION:102
+ENCODING:USASCII
+CHARSET:1252
+
+<OFX>
+<BANKMSGSRSV1><STMTTRNRS><STMTRS>
+<CURDEF>BRL
+<BANKACCTFROM>
+<BANKID>0341
+<BRANCHID>1234
+<ACCTID>123
+<ACCTTYPE>CHECKING
+</BANKACCTFROM>
+<BANKTRANLIST>
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20240105120000[-3:BRT]
+<TRNAMT>-45.90
+<FITID>0001
+<MEMO>PADARIA CENTRO
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20240106
+<TRNAMT>1500,00
+<FITID>0002
+<NAME>EMPRESA LTDA
+<MEMO>SALARIO
+</STMTTRN>
+</BANKTRANLIST>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+<CREDITCARDMSGSRSV1><CCSTMTTRNRS><CCSTMTRS>
+<CCACCTFROM>
+<ACCTID>999
+</CCACCTFROM>
+<BANKTRANLIST>
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20240110
+<TRNAMT>-89.90
+<FITID>0001
+<MEMO>STREAMING
+</STMTTRN>
+</BANKTRANLIST>
+</CCSTMTRS></CCSTMTTRNRS></CREDITCARDMSGSRSV1>
+</OFX>
+`
+
+// Extrato em OFX 2.x (XML), com todas as tags fechadas
+const ofxXML = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<?OFX OFXHEADER="200" VERSION="211" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+<OFX><BANKMSGSRSV1><STMTTRNRS><STMTRS>
+<BANKACCTFROM><BANKID>260</BANKID><ACCTID>555-1</ACCTID><ACCTTYPE>CHECKING</ACCTTYPE></BANKACCTFROM>
+<BANKTRANLIST>
+<stmttrn><trntype>PAYMENT</trntype><dtposted>20240201000000.000[-3:BRT]</dtposted><trnamt>-120.00</trnamt><fitid>abc-1</fitid><name>Conta de luz</name><memo>Conta de luz</memo></stmttrn>
+</BANKTRANLIST>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1></OFX>
+`
+
+func TestParseOFX(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want []ExtratoLancamento
+	}{
+		{
+			name: "OFX 1.x com conta e cartão",
+			data: []byte(ofxSGML),
+			want: []ExtratoLancamento{
+				{ID: "0001", Conta: "0341/123", Data: "2024-01-05", Descricao: "PADARIA CENTRO", Valor: -45.9},
+				{ID: "0002", Conta: "0341/123", Data: "2024-01-06", Descricao: "EMPRESA LTDA SALARIO", Valor: 1500},
+				{ID: "0001", Conta: "999", Data: "2024-01-10", Descricao: "STREAMING", Valor: -89.9},
+			},
+		},
+		{
+			name: "OFX 2.x com tags em minúsculas",
+			data: []byte(ofxXML),
+			want: []ExtratoLancamento{
+				{ID: "abc-1", Conta: "260/555-1", Data: "2024-02-01", Descricao: "Conta de luz", Valor: -120},
+			},
+		},
+		{
+			name: "Windows-1252",
+			data: []byte("OFXHEADER:100\n<OFX><BANKACCTFROM><BANKID>1<ACCTID>2</BANKACCTFROM><STMTTRN><DTPOSTED>20240301<TRNAMT>-5<FITID>x<MEMO>CAF\xc9 S\xc3O JO\xc3O</STMTTRN></OFX>"),
+			want: []ExtratoLancamento{
+				{ID: "x", Conta: "1/2", Data: "2024-03-01", Descricao: "CAFÉ SÃO JOÃO", Valor: -5},
+			},
+		},
+		{
+			name: "sem transações",
+			data: []byte("OFXHEADER:100\n<OFX><BANKTRANLIST></BANKTRANLIST></OFX>"),
+			want: []ExtratoLancamento{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !IsOFX(tt.data) {
+				t.Fatal("IsOFX = false, esperado true")
+			}
+			got, err := ParseOFX(tt.data)
+			if err != nil {
+				t.Fatalf("ParseOFX: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseOFX = %+v, esperado %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseOFXInvalido(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "não é OFX", data: "Data;Descrição;Valor\n01/02/2024;Padaria;-12,50\n"},
+		{name: "sem DTPOSTED", data: "<OFX><STMTTRN><TRNAMT>-5<FITID>1</STMTTRN></OFX>"},
+		{name: "DTPOSTED inválido", data: "<OFX><STMTTRN><DTPOSTED>20241399<TRNAMT>-5<FITID>1</STMTTRN></OFX>"},
+		{name: "valor inválido", data: "<OFX><STMTTRN><DTPOSTED>20240105<TRNAMT>cinco<FITID>1</STMTTRN></OFX>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := ParseOFX([]byte(tt.data)); err == nil {
+				t.Fatalf("ParseOFX = %+v, esperado erro", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// Limite do XML descompactado de cada parte do XLSX, contra arquivos zip que se expandem demais
+	maxXLSXPartSize = 32 << 20
+	// Última coluna de uma planilha do Excel (XFD)
+	maxXLSXColumns = 16384
+)
+
+// ReadSpreadsheet lê um arquivo CSV ou XLSX e retorna as linhas da primeira planilha
+func ReadSpreadsheet(data []byte) ([][]string, error) {
+	if bytes.HasPrefix(data, []byte("PK")) {
+		return readXLSX(data)
+	}
+	return readCSV(data)
+}
+
+func readCSV(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if !utf8.Valid(data) {
+		data = latin1ToUTF8(data)
+	}
+
+	// Exportações brasileiras costumam usar ponto e vírgula
+	firstLine := string(data)
+	if i := strings.IndexByte(firstLine, '\n'); i >= 0 {
+		firstLine = firstLine[:i]
+	}
+	delimiter := ','
+	if strings.Count(firstLine, ";") > strings.Count(firstLine, ",") {
+		delimiter = ';'
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler CSV: %w", err)
+	}
+	return rows, nil
+}
+
+func latin1ToUTF8(data []byte) []byte {
+	runes := make([]rune, len(data))
+	for i, b := range data {
+		runes[i] = rune(b)
+	}
+	return []byte(string(runes))
+}
+
+type xlsxSharedStrings struct {
+	Items []struct {
+		Text string `xml:"t"`
+		Runs []struct {
+			Text string `xml:"t"`
+		} `xml:"r"`
+	} `xml:"si"`
+}
+
+type xlsxWorksheet struct {
+	Rows []struct {
+		Cells []struct {
+			Ref       string `xml:"r,attr"`
+			Type      string `xml:"t,attr"`
+			Value     string `xml:"v"`
+			InlineStr struct {
+				Text string `xml:"t"`
+			} `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// readXLSX extrai as células da primeira planilha de um arquivo XLSX
+func readXLSX(data []byte) ([][]string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("arquivo XLSX inválido: %w", err)
+	}
+
+	files := make(map[string]*zip.File)
+	var firstSheet string
+	for _, f := range archive.File {
+		files[f.Name] = f
+		if strings.HasPrefix(f.Name, "xl/worksheets/sheet") && (firstSheet == "" || f.Name < firstSheet) {
+			firstSheet = f.Name
+		}
+	}
+	if files["xl/worksheets/sheet1.xml"] != nil {
+		firstSheet = "xl/worksheets/sheet1.xml"
+	}
+	if firstSheet == "" {
+		return nil, fmt.Errorf("arquivo XLSX sem planilhas")
+	}
+
+	var shared []string
+	if f := files[path.Join("xl", "sharedStrings.xml")]; f != nil {
+		var sst xlsxSharedStrings
+		if err := decodeZipXML(f, &sst); err != nil {
+			return nil, err
+		}
+		for _, item := range sst.Items {
+			text := item.Text
+			for _, r := range item.Runs {
+				text += r.Text
+			}
+			shared = append(shared, text)
+		}
+	}
+
+	var sheet xlsxWorksheet
+	if err := decodeZipXML(files[firstSheet], &sheet); err != nil {
+		return nil, err
+	}
+
+	rows := make([][]string, 0, len(sheet.Rows))
+	for _, row := range sheet.Rows {
+		var values []string
+		for i, cell := range row.Cells {
+			col := i
+			if cell.Ref != "" {
+				col = columnIndex(cell.Ref)
+			}
+			if col < 0 || col >= maxXLSXColumns {
+				return nil, fmt.Errorf("referência de célula inválida: %q", cell.Ref)
+			}
+			for len(values) <= col {
+				values = append(values, "")
+			}
+
+			switch cell.Type {
+			case "s":
+				if idx, err := strconv.Atoi(cell.Value); err == nil && idx >= 0 && idx < len(shared) {
+					values[col] = shared[idx]
+				}
+			case "inlineStr":
+				values[col] = cell.InlineStr.Text
+			default:
+				values[col] = cell.Value
+			}
+		}
+		rows = append(rows, values)
+	}
+
+	return rows, nil
+}
+
+func decodeZipXML(f *zip.File, v interface{}) error {
+	if f.UncompressedSize64 > maxXLSXPartSize {
+		return fmt.Errorf("arquivo XLSX grande demais: %s", f.Name)
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	// O tamanho declarado no zip pode ser falso, então a leitura também é limitada
+	content, err := io.ReadAll(io.LimitReader(rc, maxXLSXPartSize+1))
+	if err != nil {
+		return err
+	}
+	if len(content) > maxXLSXPartSize {
+		return fmt.Errorf("arquivo XLSX grande demais: %s", f.Name)
+	}
+	return xml.Unmarshal(content, v)
+}
+
+// columnIndex converte a referência da célula (ex: "C12") no índice da coluna (2);
+// retorna -1 quando a referência não começa com letras
+func columnIndex(ref string) int {
+	col := 0
+	for _, r := range strings.ToUpper(ref) {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		col = col*26 + int(r-'A'+1)
+		if col > maxXLSXColumns {
+			return -1
+		}
+	}
+	return col - 1
+}
+
+// ParseNumber interpreta números no formato brasileiro ("R$ 1.234,56") ou decimal simples ("1234.56")
+func ParseNumber(s string) (float64, error) {
+	s = strings.TrimSpace(strings.ReplaceAll(s, "R$", ""))
+	s = strings.ReplaceAll(s, " ", "")
+	if s == "" || s == "-" {
+		return 0, nil
+	}
+	if strings.Contains(s, ",") {
+		s = strings.ReplaceAll(s, ".", "")
+		s = strings.ReplaceAll(s, ",", ".")
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+// ParseDate aceita dd/mm/aaaa, aaaa-mm-dd e o número serial de datas do Excel
+func ParseDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range []string{"02/01/2006", "2006-01-02", "02/01/06", "20060102"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	if serial, err := strconv.ParseFloat(s, 64); err == nil && serial > 0 {
+		return time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC).AddDate(0, 0, int(serial)), nil
+	}
+	return time.Time{}, fmt.Errorf("data inválida: %q", s)
+}
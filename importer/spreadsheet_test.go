@@ -0,0 +1,234 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// buildXLSX monta um XLSX mínimo em memória com a planilha e, se houver, as strings compartilhadas
+func buildXLSX(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func sheetXML(rows string) string {
+	return `<?xml version="1.0" encoding="UTF-8"?><worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` + rows + `</sheetData></worksheet>`
+}
+
+func TestReadXLSX(t *testing.T) {
+	shared := `<sst><si><t>Data do Negócio</t></si><si><t>Código</t></si><si><r><t>PETR</t></r><r><t>4</t></r></si></sst>`
+
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  [][]string
+	}{
+		{
+			name: "strings compartilhadas, texto rico e números",
+			files: map[string]string{
+				"xl/sharedStrings.xml":     shared,
+				"xl/worksheets/sheet1.xml": sheetXML(`<row><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c></row><row><c r="A2"><v>45292</v></c><c r="B2" t="s"><v>2</v></c></row>`),
+			},
+			want: [][]string{{"Data do Negócio", "Código"}, {"45292", "PETR4"}},
+		},
+		{
+			name: "colunas puladas e referência em minúsculas",
+			files: map[string]string{
+				"xl/worksheets/sheet1.xml": sheetXML(`<row><c r="a1" t="inlineStr"><is><t>x</t></is></c><c r="C1"><v>3</v></c></row>`),
+			},
+			want: [][]string{{"x", "", "3"}},
+		},
+		{
+			name: "células sem referência usam a posição",
+			files: map[string]string{
+				"xl/worksheets/sheet1.xml": sheetXML(`<row><c><v>1</v></c><c><v>2</v></c></row>`),
+			},
+			want: [][]string{{"1", "2"}},
+		},
+		{
+			name: "primeira planilha quando não há sheet1",
+			files: map[string]string{
+				"xl/worksheets/sheet3.xml": sheetXML(`<row><c r="A1"><v>3</v></c></row>`),
+				"xl/worksheets/sheet2.xml": sheetXML(`<row><c r="A1"><v>2</v></c></row>`),
+			},
+			want: [][]string{{"2"}},
+		},
+		{
+			name: "índice de string compartilhada inexistente fica vazio",
+			files: map[string]string{
+				"xl/sharedStrings.xml":     shared,
+				"xl/worksheets/sheet1.xml": sheetXML(`<row><c r="A1" t="s"><v>99</v></c></row>`),
+			},
+			want: [][]string{{""}},
+		},
+		{
+			name: "índice de string compartilhada negativo fica vazio",
+			files: map[string]string{
+				"xl/sharedStrings.xml":     shared,
+				"xl/worksheets/sheet1.xml": sheetXML(`<row><c r="A1" t="s"><v>-1</v></c></row>`),
+			},
+			want: [][]string{{""}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := ReadSpreadsheet(buildXLSX(t, tt.files))
+			if err != nil {
+				t.Fatalf("ReadSpreadsheet: %v", err)
+			}
+			if !reflect.DeepEqual(rows, tt.want) {
+				t.Errorf("ReadSpreadsheet = %q, esperado %q", rows, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadXLSXInvalido(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+	}{
+		{name: "sem planilhas", files: map[string]string{"xl/workbook.xml": "<workbook/>"}},
+		{name: "referência só com número", files: map[string]string{"xl/worksheets/sheet1.xml": sheetXML(`<row><c r="1"><v>1</v></c></row>`)}},
+		{name: "coluna além de XFD", files: map[string]string{"xl/worksheets/sheet1.xml": sheetXML(`<row><c r="XFE1"><v>1</v></c></row>`)}},
+		{name: "referência longa demais", files: map[string]string{"xl/worksheets/sheet1.xml": sheetXML(`<row><c r="AAAAAAAAAAAAAAAAAAAAAAAA1"><v>1</v></c></row>`)}},
+		{name: "XML inválido", files: map[string]string{"xl/worksheets/sheet1.xml": "<worksheet><sheetData><row>"}},
+		{name: "parte grande demais", files: map[string]string{"xl/worksheets/sheet1.xml": sheetXML(strings.Repeat(" ", maxXLSXPartSize))}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rows, err := ReadSpreadsheet(buildXLSX(t, tt.files)); err == nil {
+				t.Fatalf("ReadSpreadsheet = %q, esperado erro", rows)
+			}
+		})
+	}
+
+	if _, err := ReadSpreadsheet([]byte("PK não é zip")); err == nil {
+		t.Error("ReadSpreadsheet de zip corrompido deveria falhar")
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want [][]string
+	}{
+		{
+			name: "vírgula",
+			data: []byte("data,valor\n01/02/2024,10.5\n"),
+			want: [][]string{{"data", "valor"}, {"01/02/2024", "10.5"}},
+		},
+		{
+			name: "ponto e vírgula com BOM",
+			data: []byte("\xef\xbb\xbfData;Descrição;Valor\n01/02/2024;Padaria, centro;-12,50\n"),
+			want: [][]string{{"Data", "Descrição", "Valor"}, {"01/02/2024", "Padaria, centro", "-12,50"}},
+		},
+		{
+			name: "Latin-1",
+			data: []byte("Hist\xf3rico;Valor\nCart\xe3o cr\xe9dito;1\n"),
+			want: [][]string{{"Histórico", "Valor"}, {"Cartão crédito", "1"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := ReadSpreadsheet(tt.data)
+			if err != nil {
+				t.Fatalf("ReadSpreadsheet: %v", err)
+			}
+			if !reflect.DeepEqual(rows, tt.want) {
+				t.Errorf("ReadSpreadsheet = %q, esperado %q", rows, tt.want)
+			}
+		})
+	}
+}
+
+func TestColumnIndex(t *testing.T) {
+	tests := map[string]int{
+		"A1":    0,
+		"c12":   2,
+		"Z9":    25,
+		"AA1":   26,
+		"XFD1":  maxXLSXColumns - 1,
+		"XFE1":  -1,
+		"1":     -1,
+		"":      -1,
+		"ZZZZ1": -1,
+	}
+	for ref, want := range tests {
+		if got := columnIndex(ref); got != want {
+			t.Errorf("columnIndex(%q) = %d, esperado %d", ref, got, want)
+		}
+	}
+}
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+		err  bool
+	}{
+		{in: "R$ 1.234,56", want: 1234.56},
+		{in: "-12,50", want: -12.5},
+		{in: "1234.56", want: 1234.56},
+		{in: "1.234.567,89", want: 1234567.89},
+		{in: "", want: 0},
+		{in: "-", want: 0},
+		{in: "abc", err: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseNumber(tt.in)
+		if (err != nil) != tt.err || (!tt.err && got != tt.want) {
+			t.Errorf("ParseNumber(%q) = %v, %v; esperado %v (erro: %v)", tt.in, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		err  bool
+	}{
+		{in: "31/01/2024", want: "2024-01-31"},
+		{in: "2024-01-31", want: "2024-01-31"},
+		{in: "31/01/24", want: "2024-01-31"},
+		{in: "20240131", want: "2024-01-31"},
+		{in: "45322", want: "2024-01-31"}, // serial do Excel
+		{in: " 31/01/2024 ", want: "2024-01-31"},
+		{in: "31/13/2024", err: true},
+		{in: "ontem", err: true},
+		{in: "", err: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseDate(tt.in)
+		if (err != nil) != tt.err {
+			t.Errorf("ParseDate(%q) erro = %v, esperado erro: %v", tt.in, err, tt.err)
+			continue
+		}
+		if !tt.err && got.Format(time.DateOnly) != tt.want {
+			t.Errorf("ParseDate(%q) = %s, esperado %s", tt.in, got.Format(time.DateOnly), tt.want)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"log"
 	"os"
+	"time"
 
 	"go-br-finance-api/config"
 	"go-br-finance-api/handlers"
@@ -42,9 +43,31 @@ func main() {
 	// Conectar Redis
 	config.ConnectRedis()
 
+	// Configurar armazenamento das conversas do chat
+	config.ConnectChatStore()
+
+	// Configurar envio de notificações push
+	config.ConnectNotifier()
+
+	// Configurar provedor de busca na web do chat
+	config.ConnectSearchProvider()
+
 	// Executar migrações
 	runMigrations()
 
+	// Workers em segundo plano
+	handlers.StartMoversRefresher(5 * time.Minute)
+	handlers.StartFundamentalsRefresher(6 * time.Hour)
+
+	streamInterval := 15 * time.Second
+	if v, err := time.ParseDuration(os.Getenv("STREAM_POLL_INTERVAL")); err == nil && v > 0 {
+		streamInterval = v
+	}
+	handlers.StartQuoteStreamer(streamInterval)
+	handlers.StartAlertWorker(time.Minute)
+	handlers.StartDailySummaryJob()
+	handlers.StartContentIndexer(time.Hour)
+
 	// Criar router
 	r := gin.Default()
 
@@ -60,6 +83,12 @@ func main() {
 	r.PUT("/recomendacoes/:id", handlers.UpdateRecomendacao)
 	r.DELETE("/recomendacoes/:id", handlers.DeleteRecomendacao)
 
+	// Articles endpoints (base de conhecimento do chat)
+	r.GET("/artigos", handlers.GetArtigos)
+	r.POST("/artigos", handlers.RequireAdmin(), handlers.CreateArtigo)
+	r.PUT("/artigos/:id", handlers.RequireAdmin(), handlers.UpdateArtigo)
+	r.DELETE("/artigos/:id", handlers.RequireAdmin(), handlers.DeleteArtigo)
+
 	// Calculations endpoints
 	r.GET("/calculations/currency", handlers.GetCurrencyConversion)
 	r.GET("/calculations/inflation", handlers.GetInflationData)
@@ -69,9 +98,70 @@ func main() {
 	r.POST("/chat", handlers.ChatWithOllama)
 	r.GET("/chat", handlers.GetChat)
 	r.DELETE("/chat", handlers.DeleteChat)
+	r.GET("/chat/sessions", handlers.GetChatSessions)
+	r.PATCH("/chat/sessions/:id", handlers.RenameChatSession)
+	r.GET("/chat/profiles", handlers.GetChatProfiles)
+	r.POST("/chat/profiles", handlers.RequireChatAdmin(), handlers.CreateChatProfile)
+	r.PUT("/chat/profiles/:nome", handlers.RequireChatAdmin(), handlers.UpdateChatProfile)
+	r.DELETE("/chat/profiles/:nome", handlers.RequireChatAdmin(), handlers.DeleteChatProfile)
 
 	// Stocks endpoint
 	r.GET("/stocks", handlers.GetStocks)
+	r.GET("/stocks/movers", handlers.GetMarketMovers)
+	r.GET("/stocks/:symbol/fundamentals", handlers.GetFundamentals)
+	r.POST("/stocks/screener", handlers.ScreenStocks)
+	r.GET("/stocks/stream", handlers.StreamQuotes)
+
+	// Portfolio endpoints (usuário identificado pelo cabeçalho X-User-ID)
+	r.GET("/portfolio", handlers.GetPortfolio)
+	r.GET("/portfolio/performance", handlers.GetPortfolioPerformance)
+	r.POST("/portfolio/import", handlers.ImportPortfolio)
+	r.GET("/portfolio/irpf/:year", handlers.GetIRPFReport)
+	r.POST("/portfolio/rebalance", handlers.RebalancePortfolio)
+	r.GET("/portfolio/income", handlers.GetProventos)
+	r.POST("/portfolio/income", handlers.CreateProvento)
+	r.DELETE("/portfolio/income/:id", handlers.DeleteProvento)
+	r.GET("/portfolio/transactions", handlers.GetPortfolioTransactions)
+	r.POST("/portfolio/transactions", handlers.CreatePortfolioTransaction)
+	r.DELETE("/portfolio/transactions/:id", handlers.DeletePortfolioTransaction)
+
+	// Alerts endpoints
+	r.GET("/alerts", handlers.GetAlerts)
+	r.POST("/alerts", handlers.CreateAlert)
+	r.DELETE("/alerts/:id", handlers.DeleteAlert)
+
+	// Budget endpoints
+	r.GET("/budget/summary", handlers.GetBudgetSummary)
+	r.GET("/budget/categories", handlers.GetBudgetCategories)
+	r.POST("/budget/categories", handlers.CreateBudgetCategory)
+	r.DELETE("/budget/categories/:id", handlers.DeleteBudgetCategory)
+	r.GET("/budget/limits", handlers.GetBudgetLimits)
+	r.PUT("/budget/limits", handlers.SetBudgetLimit)
+	r.GET("/budget/transactions", handlers.GetBudgetTransactions)
+	r.POST("/budget/transactions", handlers.CreateBudgetTransaction)
+	r.DELETE("/budget/transactions/:id", handlers.DeleteBudgetTransaction)
+	r.GET("/budget/recurring", handlers.GetBudgetRecurring)
+	r.POST("/budget/recurring", handlers.CreateBudgetRecurring)
+	r.DELETE("/budget/recurring/:id", handlers.DeleteBudgetRecurring)
+	r.POST("/budget/import", handlers.ImportBudgetStatement)
+	r.GET("/budget/rules", handlers.GetBudgetRules)
+	r.POST("/budget/rules", handlers.CreateBudgetRule)
+	r.DELETE("/budget/rules/:id", handlers.DeleteBudgetRule)
+
+	// Tools endpoints
+	r.POST("/tools/boleto/parse", handlers.ParseBoleto)
+	r.POST("/tools/pix/generate", handlers.GeneratePix)
+	r.POST("/tools/pix/decode", handlers.DecodePix)
+	r.POST("/tools/validate/:tipo", handlers.ValidateDocument)
+
+	// Daily summary endpoint
+	r.GET("/summary/:date", handlers.GetDailySummary)
+
+	// Notification endpoints
+	r.POST("/notifications/devices", handlers.RegisterDevice)
+	r.DELETE("/notifications/devices/:token", handlers.UnregisterDevice)
+	r.GET("/notifications/topics", handlers.GetNotificationTopics)
+	r.PUT("/notifications/topics", handlers.UpdateNotificationTopics)
 
 	// Swagger docs endpoint
 	r.GET("/docs/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
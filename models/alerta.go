@@ -0,0 +1,32 @@
+package models
+
+// Tipos de alerta
+const (
+	AlertaAcao   = "acao"
+	AlertaCambio = "cambio"
+)
+
+// Situação de um alerta
+const (
+	AlertaAtivo     = "ativo"
+	AlertaDisparado = "disparado"
+)
+
+type Alerta struct {
+	ID           int      `db:"id" json:"id"`
+	UserID       string   `db:"user_id" json:"-"`
+	Tipo         string   `db:"tipo" json:"tipo"`         // acao ou cambio
+	Symbol       string   `db:"symbol" json:"symbol"`     // ex: PETR4 ou USD
+	Condicao     string   `db:"condicao" json:"condicao"` // acima ou abaixo
+	Valor        float64  `db:"valor" json:"valor"`
+	WebhookURL   string   `db:"webhook_url" json:"webhook_url"`
+	Segredo      string   `db:"segredo" json:"-"`
+	Status       string   `db:"status" json:"status"`
+	ValorDisparo *float64 `db:"valor_disparo" json:"valor_disparo,omitempty"`
+	DisparadoEm  *string  `db:"disparado_em" json:"disparado_em,omitempty"`
+	EntregueEm   *string  `db:"entregue_em" json:"entregue_em,omitempty"`
+	Tentativas   int      `db:"tentativas" json:"tentativas"`
+	UltimoErro   string   `db:"ultimo_erro" json:"ultimo_erro,omitempty"`
+	ProximoEnvio *string  `db:"proximo_envio" json:"proximo_envio,omitempty"` // próxima tentativa de entrega do webhook
+	CreatedAt    string   `db:"created_at" json:"created_at,omitempty"`
+}
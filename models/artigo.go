@@ -0,0 +1,11 @@
+package models
+
+// Artigo é um conteúdo educacional curado usado como base de conhecimento do chat
+type Artigo struct {
+	ID        int    `db:"id" json:"id"`
+	Titulo    string `db:"titulo" json:"titulo"`
+	Conteudo  string `db:"conteudo" json:"conteudo"`
+	URL       string `db:"url" json:"url,omitempty"`
+	CreatedAt string `db:"created_at" json:"created_at,omitempty"`
+	UpdatedAt string `db:"updated_at" json:"updated_at,omitempty"`
+}
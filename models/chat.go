@@ -1,11 +1,51 @@
 package models
 
 type Message struct {
-	Role    string `json:"role"` // "user" or "assistant"
-	Content string `json:"content"`
+	Role      string `db:"role" json:"role"` // "user" or "assistant"
+	Content   string `db:"content" json:"content"`
+	CreatedAt string `db:"created_at" json:"created_at,omitempty"`
+	Model     string `db:"model" json:"model,omitempty"` // model that wrote an assistant message
+	// Tokens is the length of the message: the estimate for user messages and the
+	// count reported by Ollama for assistant messages
+	Tokens int `db:"tokens" json:"tokens,omitempty"`
+	// PromptTokens is the prompt size Ollama reported to generate an assistant message
+	PromptTokens int `db:"prompt_tokens" json:"prompt_tokens,omitempty"`
 }
 
 type Conversation struct {
-	SessionID string    `json:"session_id"`
-	Messages  []Message `json:"messages"`
+	SessionID string    `db:"session_id" json:"session_id"`
+	UserID    string    `db:"user_id" json:"user_id,omitempty"`
+	Title     string    `db:"title" json:"title,omitempty"`
+	CreatedAt string    `db:"created_at" json:"created_at,omitempty"`
+	UpdatedAt string    `db:"updated_at" json:"updated_at,omitempty"`
+	Messages  []Message `db:"-" json:"messages"`
+	// Summary condenses the first SummarizedCount messages, which are no longer sent to the model
+	Summary         string `db:"summary" json:"summary,omitempty"`
+	SummarizedCount int    `db:"summarized_count" json:"summarized_count,omitempty"`
+	// StoredMessages is how many messages the store had when the conversation was loaded;
+	// Save uses it to detect another request writing to the same session in the meantime
+	StoredMessages int `db:"-" json:"-"`
+}
+
+// ChatSession is a conversation as listed in GET /chat/sessions, without its messages
+type ChatSession struct {
+	SessionID    string `db:"session_id" json:"session_id"`
+	Title        string `db:"title" json:"title"`
+	CreatedAt    string `db:"created_at" json:"created_at"`
+	UpdatedAt    string `db:"updated_at" json:"updated_at"`
+	MessageCount int    `db:"message_count" json:"message_count"`
+}
+
+// PerfilChat is a named system prompt, optionally with its own model and parameters
+type PerfilChat struct {
+	ID            int      `db:"id" json:"id"`
+	Nome          string   `db:"nome" json:"nome"`
+	Descricao     string   `db:"descricao" json:"descricao"`
+	SystemPrompt  string   `db:"system_prompt" json:"system_prompt"`
+	Modelo        *string  `db:"modelo" json:"modelo,omitempty"`
+	Temperatura   *float64 `db:"temperatura" json:"temperatura,omitempty"`
+	MaxTokens     *int     `db:"max_tokens" json:"max_tokens,omitempty"`
+	ContextWindow *int     `db:"context_window" json:"context_window,omitempty"`
+	CreatedAt     string   `db:"created_at" json:"created_at,omitempty"`
+	UpdatedAt     string   `db:"updated_at" json:"updated_at,omitempty"`
 }
@@ -0,0 +1,17 @@
+package models
+
+// Tópicos de notificação push
+const (
+	TopicoAlertas       = "alertas"
+	TopicoRecomendacoes = "recomendacoes"
+	TopicoResumoDiario  = "resumo_diario"
+)
+
+type Dispositivo struct {
+	ID         int    `db:"id" json:"id"`
+	UserID     string `db:"user_id" json:"-"`
+	Token      string `db:"token" json:"token" binding:"required"`
+	Plataforma string `db:"plataforma" json:"plataforma"` // android ou ios
+	CreatedAt  string `db:"created_at" json:"created_at,omitempty"`
+	UpdatedAt  string `db:"updated_at" json:"updated_at,omitempty"`
+}
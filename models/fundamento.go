@@ -0,0 +1,24 @@
+package models
+
+// Fundamento guarda os indicadores de uma empresa em um trimestre. Receita, lucro e patrimônio vêm
+// dos demonstrativos trimestrais, em reais; os múltiplos de mercado (P/L, P/VP, DY, EV/EBITDA) são os
+// lidos durante o trimestre. Percentuais (ROE e dividend yield dos últimos 12 meses, margem líquida)
+// são expressos em %: 6.5 = 6,5%.
+type Fundamento struct {
+	ID                  int      `db:"id" json:"id"`
+	Symbol              string   `db:"symbol" json:"symbol"`
+	Ano                 int      `db:"ano" json:"ano"`
+	Trimestre           int      `db:"trimestre" json:"trimestre"`
+	PL                  *float64 `db:"pl" json:"pl"`
+	PVP                 *float64 `db:"pvp" json:"pvp"`
+	ROE                 *float64 `db:"roe" json:"roe"`
+	MargemLiquida       *float64 `db:"margem_liquida" json:"margem_liquida"`
+	DividaLiquidaEbitda *float64 `db:"divida_liquida_ebitda" json:"divida_liquida_ebitda"`
+	DividendYield       *float64 `db:"dividend_yield" json:"dividend_yield"`
+	EvEbitda            *float64 `db:"ev_ebitda" json:"ev_ebitda"`
+	ReceitaLiquida      *float64 `db:"receita_liquida" json:"receita_liquida"`
+	LucroLiquido        *float64 `db:"lucro_liquido" json:"lucro_liquido"`
+	PatrimonioLiquido   *float64 `db:"patrimonio_liquido" json:"patrimonio_liquido"`
+	CreatedAt           string   `db:"created_at" json:"created_at,omitempty"`
+	UpdatedAt           string   `db:"updated_at" json:"updated_at,omitempty"`
+}
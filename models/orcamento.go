@@ -0,0 +1,60 @@
+package models
+
+// Tipos de lançamento do orçamento
+const (
+	LancamentoDespesa = "despesa"
+	LancamentoReceita = "receita"
+)
+
+type Categoria struct {
+	ID        int    `db:"id" json:"id"`
+	UserID    string `db:"user_id" json:"-"`
+	Nome      string `db:"nome" json:"nome" binding:"required"`
+	Tipo      string `db:"tipo" json:"tipo"` // despesa ou receita
+	CreatedAt string `db:"created_at" json:"created_at,omitempty"`
+}
+
+// LimiteOrcamento é o valor planejado para uma categoria em um mês
+type LimiteOrcamento struct {
+	CategoriaID int     `db:"categoria_id" json:"categoria_id" binding:"required"`
+	Mes         string  `db:"mes" json:"mes" binding:"required"` // YYYY-MM
+	Valor       float64 `db:"valor" json:"valor"`
+}
+
+type Lancamento struct {
+	ID            int     `db:"id" json:"id"`
+	UserID        string  `db:"user_id" json:"-"`
+	CategoriaID   *int    `db:"categoria_id" json:"categoria_id"`
+	Descricao     string  `db:"descricao" json:"descricao"`
+	Tipo          string  `db:"tipo" json:"tipo"` // despesa ou receita
+	Valor         float64 `db:"valor" json:"valor"`
+	Data          string  `db:"data" json:"data"` // YYYY-MM-DD
+	RecorrenciaID *int    `db:"recorrencia_id" json:"recorrencia_id,omitempty"`
+	Conta         string  `db:"conta" json:"conta,omitempty"` // conta do extrato importado (BANKID/ACCTID)
+	FITID         *string `db:"fitid" json:"fitid,omitempty"` // identificador do extrato importado
+	CreatedAt     string  `db:"created_at" json:"created_at,omitempty"`
+}
+
+// RegraCategoria atribui a categoria a lançamentos importados cuja descrição contém o padrão
+type RegraCategoria struct {
+	ID          int    `db:"id" json:"id"`
+	UserID      string `db:"user_id" json:"-"`
+	Padrao      string `db:"padrao" json:"padrao" binding:"required"` // ex: IFOOD
+	CategoriaID int    `db:"categoria_id" json:"categoria_id" binding:"required"`
+	CreatedAt   string `db:"created_at" json:"created_at,omitempty"`
+}
+
+// Recorrencia gera um lançamento por mês no dia indicado, de inicio até fim (opcional)
+type Recorrencia struct {
+	ID          int     `db:"id" json:"id"`
+	UserID      string  `db:"user_id" json:"-"`
+	CategoriaID *int    `db:"categoria_id" json:"categoria_id"`
+	Descricao   string  `db:"descricao" json:"descricao"`
+	Tipo        string  `db:"tipo" json:"tipo"`
+	Valor       float64 `db:"valor" json:"valor"`
+	Dia         int     `db:"dia" json:"dia"` // 1 a 31; meses mais curtos usam o último dia
+	Inicio      string  `db:"inicio" json:"inicio"`
+	Fim         *string `db:"fim" json:"fim,omitempty"`
+	GeradoAte   *string `db:"gerado_ate" json:"gerado_ate,omitempty"`
+	CreatedAt   string  `db:"created_at" json:"created_at,omitempty"`
+}
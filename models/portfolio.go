@@ -0,0 +1,77 @@
+package models
+
+// Tipos de ativo aceitos na carteira
+const (
+	TipoAcao      = "acao"
+	TipoFII       = "fii"
+	TipoETF       = "etf"
+	TipoBDR       = "bdr"
+	TipoTesouro   = "tesouro"
+	TipoRendaFixa = "renda_fixa"
+)
+
+// Operações de uma transação
+const (
+	OperacaoCompra = "compra"
+	OperacaoVenda  = "venda"
+)
+
+type Transacao struct {
+	ID         int     `db:"id" json:"id"`
+	UserID     string  `db:"user_id" json:"-"`
+	TipoAtivo  string  `db:"tipo_ativo" json:"tipo_ativo"`
+	Symbol     string  `db:"symbol" json:"symbol"`
+	Operacao   string  `db:"operacao" json:"operacao"`
+	Quantidade float64 `db:"quantidade" json:"quantidade"`
+	Preco      float64 `db:"preco" json:"preco"`
+	Taxas      float64 `db:"taxas" json:"taxas"`
+	Data       string  `db:"data" json:"data"` // YYYY-MM-DD
+	CreatedAt  string  `db:"created_at" json:"created_at,omitempty"`
+}
+
+type Posicao struct {
+	TipoAtivo             string  `json:"tipo_ativo"`
+	Symbol                string  `json:"symbol"`
+	Quantidade            float64 `json:"quantidade"`
+	PrecoMedio            float64 `json:"preco_medio"`
+	CustoTotal            float64 `json:"custo_total"`
+	PrecoAtual            float64 `json:"preco_atual"`
+	ValorAtual            float64 `json:"valor_atual"`
+	ResultadoNaoRealizado float64 `json:"resultado_nao_realizado"`
+	ResultadoPercentual   float64 `json:"resultado_percentual"`
+	FontePreco            string  `json:"fonte_preco"` // "cotacao" ou "custo"
+}
+
+type Alocacao struct {
+	TipoAtivo  string  `json:"tipo_ativo"`
+	Valor      float64 `json:"valor"`
+	Percentual float64 `json:"percentual"`
+}
+
+type Carteira struct {
+	Posicoes              []Posicao  `json:"posicoes"`
+	Alocacao              []Alocacao `json:"alocacao"`
+	CustoTotal            float64    `json:"custo_total"`
+	ValorTotal            float64    `json:"valor_total"`
+	ResultadoNaoRealizado float64    `json:"resultado_nao_realizado"`
+	ResultadoRealizado    float64    `json:"resultado_realizado"`
+}
+
+// Tipos de provento
+const (
+	ProventoDividendo  = "dividendo"
+	ProventoJCP        = "jcp"
+	ProventoRendimento = "rendimento"
+)
+
+type Provento struct {
+	ID          int     `db:"id" json:"id"`
+	UserID      string  `db:"user_id" json:"-"`
+	Tipo        string  `db:"tipo" json:"tipo"`
+	TipoAtivo   string  `db:"tipo_ativo" json:"tipo_ativo"`
+	Symbol      string  `db:"symbol" json:"symbol"`
+	Valor       float64 `db:"valor" json:"valor"` // valor líquido recebido
+	Data        string  `db:"data" json:"data"`   // YYYY-MM-DD
+	Instituicao string  `db:"instituicao" json:"instituicao"`
+	CreatedAt   string  `db:"created_at" json:"created_at,omitempty"`
+}
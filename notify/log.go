@@ -0,0 +1,50 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// LogSender registra as notificações em um arquivo (uma linha JSON por envio) ou no log,
+// útil para desenvolvimento e testes
+type LogSender struct {
+	mutex sync.Mutex
+	file  *os.File
+}
+
+func NewLogSender(path string) (*LogSender, error) {
+	if path == "" {
+		return &LogSender{}, nil
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &LogSender{file: file}, nil
+}
+
+func (s *LogSender) Send(ctx context.Context, token string, n Notification) error {
+	line, err := json.Marshal(struct {
+		Token        string       `json:"token"`
+		Notification Notification `json:"notification"`
+		SentAt       time.Time    `json:"sent_at"`
+	}{token, n, time.Now()})
+	if err != nil {
+		return err
+	}
+
+	if s.file == nil {
+		log.Println("🔔 Notificação:", string(line))
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	_, err = s.file.Write(append(line, '\n'))
+	return err
+}
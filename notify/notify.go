@@ -0,0 +1,34 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ErrInvalidToken indica que o token do dispositivo não é mais válido e pode ser removido
+var ErrInvalidToken = errors.New("token de dispositivo inválido")
+
+type Notification struct {
+	Title string            `json:"title"`
+	Body  string            `json:"body"`
+	Data  map[string]string `json:"data,omitempty"`
+}
+
+// Sender entrega uma notificação a um dispositivo
+type Sender interface {
+	Send(ctx context.Context, token string, n Notification) error
+}
+
+// NewSenderFromEnv escolhe a implementação pela variável NOTIFY_SENDER (fcm ou log)
+func NewSenderFromEnv() (Sender, error) {
+	switch os.Getenv("NOTIFY_SENDER") {
+	case "fcm":
+		return NewFCMSender(os.Getenv("FCM_CREDENTIALS_FILE"))
+	case "", "log":
+		return NewLogSender(os.Getenv("NOTIFY_LOG_FILE"))
+	default:
+		return nil, fmt.Errorf("NOTIFY_SENDER desconhecido: %s", os.Getenv("NOTIFY_SENDER"))
+	}
+}
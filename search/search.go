@@ -0,0 +1,219 @@
+package search
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Níveis de relevância, do mais forte para o mais fraco
+const (
+	rankExactKey = iota
+	rankKeyPrefix
+	rankExactWord
+	rankWordPrefix
+	rankSubstring
+	rankFuzzy
+	noMatch
+)
+
+type Entry struct {
+	Key  string // Código de negociação (ex: ITUB4)
+	Text string // Nome descritivo (ex: Itaú Unibanco)
+}
+
+type indexedEntry struct {
+	key   string
+	text  string
+	words []string
+}
+
+type Index struct {
+	entries []indexedEntry
+	byKey   map[string]int
+}
+
+type result struct {
+	pos      int
+	rank     int
+	distance int
+}
+
+// Fold normaliza o texto para busca: minúsculas, sem acentos e sem pontuação
+func Fold(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(t, s)
+	if err != nil {
+		folded = s
+	}
+
+	folded = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return ' '
+	}, folded)
+
+	return strings.Join(strings.Fields(folded), " ")
+}
+
+// NewIndex pré-processa as entradas para buscas repetidas
+func NewIndex(entries []Entry) *Index {
+	idx := &Index{
+		entries: make([]indexedEntry, len(entries)),
+		byKey:   make(map[string]int, len(entries)),
+	}
+
+	for i, e := range entries {
+		key := Fold(e.Key)
+		text := Fold(e.Text)
+		idx.entries[i] = indexedEntry{key: key, text: text, words: strings.Fields(text)}
+		if _, exists := idx.byKey[key]; !exists {
+			idx.byKey[key] = i
+		}
+	}
+
+	return idx
+}
+
+// Search retorna as posições das entradas que correspondem à consulta, ordenadas por relevância
+func (idx *Index) Search(query string, limit int) []int {
+	tokens := strings.Fields(Fold(query))
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	// Consulta completa igual ao ticker sempre vem primeiro
+	exactPos, hasExact := idx.byKey[strings.Join(tokens, "")]
+
+	var results []result
+	for pos, entry := range idx.entries {
+		// Todos os termos precisam corresponder; vale a pior relevância entre eles
+		rank, dist := rankExactKey, 0
+		for _, token := range tokens {
+			r, d := matchToken(entry, token)
+			if r > rank {
+				rank = r
+			}
+			dist += d
+		}
+		if hasExact && pos == exactPos {
+			rank, dist = rankExactKey, 0
+		}
+		if rank == noMatch {
+			continue
+		}
+		results = append(results, result{pos: pos, rank: rank, distance: dist})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].rank != results[j].rank {
+			return results[i].rank < results[j].rank
+		}
+		return results[i].distance < results[j].distance
+	})
+
+	if limit > 0 && limit < len(results) {
+		results = results[:limit]
+	}
+
+	positions := make([]int, len(results))
+	for i, r := range results {
+		positions[i] = r.pos
+	}
+	return positions
+}
+
+// matchToken classifica um único termo da consulta contra uma entrada
+func matchToken(entry indexedEntry, token string) (int, int) {
+	switch {
+	case entry.key == token:
+		return rankExactKey, 0
+	case strings.HasPrefix(entry.key, token):
+		return rankKeyPrefix, 0
+	}
+
+	for _, word := range entry.words {
+		if word == token {
+			return rankExactWord, 0
+		}
+	}
+	for _, word := range entry.words {
+		if strings.HasPrefix(word, token) {
+			return rankWordPrefix, 0
+		}
+	}
+
+	if strings.Contains(entry.key, token) || strings.Contains(entry.text, token) {
+		return rankSubstring, 0
+	}
+
+	maxTypos := allowedTypos(token)
+	if maxTypos == 0 {
+		return noMatch, 0
+	}
+
+	best := maxTypos + 1
+	candidates := append([]string{entry.key}, entry.words...)
+	for _, word := range candidates {
+		if d := distance(token, word); d < best {
+			best = d
+		}
+		// Permite erro de digitação em consultas incompletas ("petrob" → "petrobras")
+		if prefix := []rune(word); len(prefix) > len([]rune(token)) {
+			if d := distance(token, string(prefix[:len([]rune(token))])); d < best {
+				best = d
+			}
+		}
+	}
+	if best <= maxTypos {
+		return rankFuzzy, best
+	}
+
+	return noMatch, 0
+}
+
+// allowedTypos define quantos erros de digitação são tolerados pelo tamanho do termo
+func allowedTypos(token string) int {
+	switch n := len([]rune(token)); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// distance calcula a distância de Damerau-Levenshtein (com transposição adjacente)
+func distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+
+	return prev[len(rb)]
+}
@@ -0,0 +1,91 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFold(t *testing.T) {
+	tests := map[string]string{
+		"Itaú Unibanco":         "itau unibanco",
+		"  PETROBRAS   PN  ":    "petrobras pn",
+		"Ações/ON-NM":           "acoes on nm",
+		"São Paulo, Alpargatas": "sao paulo alpargatas",
+		"":                      "",
+	}
+	for in, want := range tests {
+		if got := Fold(in); got != want {
+			t.Errorf("Fold(%q) = %q, esperado %q", in, got, want)
+		}
+	}
+}
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"petrobras", "petrobras", 0},
+		{"petrobas", "petrobras", 1},  // omissão
+		{"petorbras", "petrobras", 1}, // transposição
+		{"vale", "vali", 1},           // troca
+		{"itau", "", 4},
+		{"", "", 0},
+	}
+	for _, tt := range tests {
+		if got := distance(tt.a, tt.b); got != tt.want {
+			t.Errorf("distance(%q, %q) = %d, esperado %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestIndexSearch(t *testing.T) {
+	entries := []Entry{
+		{Key: "PETR4", Text: "Petrobras PN"},
+		{Key: "PETR3", Text: "Petrobras ON"},
+		{Key: "VALE3", Text: "Vale ON"},
+		{Key: "ITUB4", Text: "Itaú Unibanco PN"},
+		{Key: "ITSA4", Text: "Itaúsa PN"},
+		{Key: "BBAS3", Text: "Banco do Brasil ON"},
+		{Key: "SBSP3", Text: "Sabesp ON"},
+		{Key: "PRIO3", Text: "PetroRio ON"},
+	}
+	idx := NewIndex(entries)
+
+	keys := func(positions []int) []string {
+		out := []string{}
+		for _, p := range positions {
+			out = append(out, entries[p].Key)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		limit int
+		want  []string
+	}{
+		{name: "ticker exato antes dos parecidos", query: "petr4", want: []string{"PETR4", "PETR3", "PRIO3"}},
+		{name: "prefixo do ticker antes de prefixo do nome", query: "PETR", want: []string{"PETR4", "PETR3", "PRIO3"}},
+		{name: "palavra exata antes de prefixo", query: "itau", want: []string{"ITUB4", "ITSA4"}},
+		{name: "consulta com acento", query: "Itaú", want: []string{"ITUB4", "ITSA4"}},
+		{name: "todos os termos precisam casar", query: "petrobras on", want: []string{"PETR3"}},
+		{name: "nome da empresa", query: "vale", want: []string{"VALE3"}},
+		{name: "erro de digitação", query: "petrobas", want: []string{"PETR4", "PETR3"}},
+		{name: "erro de digitação em consulta incompleta", query: "petorb", want: []string{"PETR4", "PETR3"}},
+		{name: "substring", query: "unibanc", want: []string{"ITUB4"}},
+		{name: "termo curto sem tolerância", query: "xyz", want: []string{}},
+		{name: "limite", query: "on", limit: 2, want: []string{"PETR3", "VALE3"}},
+		{name: "consulta vazia", query: "  ", want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := keys(idx.Search(tt.query, tt.limit))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Search(%q) = %v, esperado %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package websearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const braveEndpoint = "https://api.search.brave.com/res/v1/web/search"
+
+// Brave usa a Brave Search API
+type Brave struct {
+	apiKey string
+}
+
+func NewBrave(apiKey string) (*Brave, error) {
+	if apiKey == "" {
+		return nil, errors.New("BRAVE_API_KEY não configurado")
+	}
+	return &Brave{apiKey: apiKey}, nil
+}
+
+func (b *Brave) Search(ctx context.Context, query string, limit int) ([]Result, error) {
+	params := url.Values{"q": {query}, "count": {strconv.Itoa(limit)}, "country": {"BR"}, "search_lang": {"pt-br"}}
+	req, err := http.NewRequestWithContext(ctx, "GET", braveEndpoint+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("X-Subscription-Token", b.apiKey)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Brave Search retornou status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Web struct {
+			Results []struct {
+				Title       string `json:"title"`
+				URL         string `json:"url"`
+				Description string `json:"description"`
+			} `json:"results"`
+		} `json:"web"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+
+	results := []Result{}
+	for _, r := range body.Web.Results {
+		if len(results) == limit {
+			break
+		}
+		results = append(results, Result{Title: r.Title, URL: r.URL, Snippet: r.Description})
+	}
+	return results, nil
+}
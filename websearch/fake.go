@@ -0,0 +1,35 @@
+package websearch
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// FakeProvider retorna resultados fixos derivados da consulta, sem acesso à rede.
+// Útil em desenvolvimento e testes; Results substitui os resultados gerados.
+type FakeProvider struct {
+	Results []Result
+}
+
+func (f *FakeProvider) Search(ctx context.Context, query string, limit int) ([]Result, error) {
+	results := f.Results
+	if results == nil {
+		results = []Result{
+			{
+				Title:   fmt.Sprintf("Resultado de exemplo para %q", query),
+				URL:     "https://example.com/busca?q=" + url.QueryEscape(query),
+				Snippet: "Conteúdo fictício retornado pelo provedor de busca local.",
+			},
+			{
+				Title:   "Tesouro Direto - Preços e taxas dos títulos",
+				URL:     "https://www.tesourodireto.com.br/titulos/precos-e-taxas.htm",
+				Snippet: "Resultado fixo do provedor de busca local.",
+			},
+		}
+	}
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
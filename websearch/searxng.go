@@ -0,0 +1,61 @@
+package websearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// SearXNG consulta uma instância SearXNG própria (com o formato json habilitado em settings.yml)
+type SearXNG struct {
+	baseURL string
+}
+
+func NewSearXNG(baseURL string) (*SearXNG, error) {
+	if baseURL == "" {
+		return nil, errors.New("SEARXNG_URL não configurado")
+	}
+	return &SearXNG{baseURL: strings.TrimRight(baseURL, "/")}, nil
+}
+
+func (s *SearXNG) Search(ctx context.Context, query string, limit int) ([]Result, error) {
+	params := url.Values{"q": {query}, "format": {"json"}, "language": {"pt-BR"}}
+	req, err := http.NewRequestWithContext(ctx, "GET", s.baseURL+"/search?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("SearXNG retornou status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Results []struct {
+			Title   string `json:"title"`
+			URL     string `json:"url"`
+			Content string `json:"content"`
+		} `json:"results"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+
+	results := []Result{}
+	for _, r := range body.Results {
+		if len(results) == limit {
+			break
+		}
+		results = append(results, Result{Title: r.Title, URL: r.URL, Snippet: r.Content})
+	}
+	return results, nil
+}
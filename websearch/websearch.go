@@ -0,0 +1,40 @@
+package websearch
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Result é um resultado de busca citável na resposta do chat
+type Result struct {
+	Title   string `json:"title"`
+	URL     string `json:"url"`
+	Snippet string `json:"snippet,omitempty"`
+}
+
+// SearchProvider executa buscas na web
+type SearchProvider interface {
+	Search(ctx context.Context, query string, limit int) ([]Result, error)
+}
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// NewProviderFromEnv escolhe o provedor pela variável WEB_SEARCH_PROVIDER (searxng, brave ou fake).
+// Sem provedor configurado retorna nil e a busca fica desativada.
+func NewProviderFromEnv() (SearchProvider, error) {
+	switch os.Getenv("WEB_SEARCH_PROVIDER") {
+	case "":
+		return nil, nil
+	case "searxng":
+		return NewSearXNG(os.Getenv("SEARXNG_URL"))
+	case "brave":
+		return NewBrave(os.Getenv("BRAVE_API_KEY"))
+	case "fake":
+		return &FakeProvider{}, nil
+	default:
+		return nil, fmt.Errorf("WEB_SEARCH_PROVIDER desconhecido: %s", os.Getenv("WEB_SEARCH_PROVIDER"))
+	}
+}